@@ -1,9 +1,231 @@
 package camera
 
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
 // A Camera indicates an in-world rectangle to be drawn to an on-screen rectangle
 type Camera struct {
 	World  Rectangle
 	Screen Screen
+	// Rotation turns the camera by an angle in radians about the centre of the in-world
+	// rectangle, so that the world appears turned the other way on screen
+	Rotation float32
+	// Viewport is the part of the window that the camera draws to, as fractions of the window's
+	// width and height, such as {X: 0.5, Y: 0, Width: 0.5, Height: 1} for the right half. A
+	// viewport with no area covers the whole window
+	Viewport Rectangle
+	// Order decides which cameras draw first, from lowest to highest
+	Order int
+	// Layers is a mask of the sprite layers from 0 to 31 that the camera shows, with bit n set
+	// to show layer n, such as to keep the HUD out of a minimap. A mask of zero shows every layer,
+	// including those outside that range
+	Layers uint32
+	// Mode decides how the in-world rectangle is fitted to the viewport when the window is resized
+	Mode ScaleMode
+	// DesignWidth and DesignHeight are the size of the in-world rectangle that the game is made
+	// to show. If they are not set, they are taken from the in-world rectangle when the camera is
+	// first resized, or from the size of its viewport if that is not set either
+	DesignWidth, DesignHeight float32
+	baseWidth, baseHeight     float32
+}
+
+// A ScaleMode is a way of fitting the design size of a camera to a viewport
+type ScaleMode int
+
+const (
+	// Expand scales the design size to fit the viewport while keeping its aspect ratio, then
+	// extends the in-world rectangle along one axis to fill the viewport, showing more of the
+	// world rather than leaving bars
+	Expand ScaleMode = iota
+	// Stretch shows exactly the design size, stretched to fill the viewport
+	Stretch
+	// Letterbox shows exactly the design size, scaled as large as fits in the viewport without
+	// changing its aspect ratio and centred, leaving bars along two sides
+	Letterbox
+	// Integer is like Letterbox, but scales by a whole number so that every world unit covers
+	// the same number of pixels, for crisp pixel art. It falls back to Letterbox if the viewport
+	// is smaller than the design size
+	Integer
+)
+
+var scaleModeNames = []string{"expand", "stretch", "letterbox", "integer"}
+
+// String returns the lower case name of the scale mode
+func (m ScaleMode) String() string {
+	if m < 0 || int(m) >= len(scaleModeNames) {
+		return fmt.Sprintf("ScaleMode(%d)", int(m))
+	}
+	return scaleModeNames[m]
+}
+
+// UnmarshalText allows scale modes to be given by name, such as "letterbox", in JSON files
+func (m *ScaleMode) UnmarshalText(text []byte) error {
+	for i, name := range scaleModeNames {
+		if strings.EqualFold(string(text), name) {
+			*m = ScaleMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("camera: unknown scale mode %q", text)
+}
+
+// Resize fits the camera to its viewport of a window of the given size, setting the on-screen
+// rectangle and the size of the in-world rectangle according to the camera's scale mode. The
+// centre of the in-world rectangle stays where it is, unless it had no size, and any zoom applied
+// since the last resize is kept
+func (c *Camera) Resize(width, height int) {
+	v := c.Viewport
+	if v.Width <= 0 || v.Height <= 0 {
+		v = Rectangle{Width: 1, Height: 1}
+	}
+	x0, y0 := round(v.X*float32(width)), round(v.Y*float32(height))
+	x1, y1 := round((v.X+v.Width)*float32(width)), round((v.Y+v.Height)*float32(height))
+	c.Screen = Screen{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+	if c.Screen.Width <= 0 || c.Screen.Height <= 0 {
+		return
+	}
+
+	sized := c.World.Width > 0 && c.World.Height > 0
+	if c.DesignWidth <= 0 || c.DesignHeight <= 0 {
+		if sized {
+			c.DesignWidth, c.DesignHeight = c.World.Width, c.World.Height
+		} else {
+			c.DesignWidth, c.DesignHeight = float32(c.Screen.Width), float32(c.Screen.Height)
+		}
+	}
+	zoom := float32(1)
+	if c.baseWidth > 0 && sized {
+		zoom = c.baseWidth / c.World.Width
+	}
+	fx, fy := c.Focus()
+
+	sw, sh := float32(c.Screen.Width), float32(c.Screen.Height)
+	scale := sw / c.DesignWidth
+	if s := sh / c.DesignHeight; s < scale {
+		scale = s
+	}
+	if c.Mode == Integer && scale >= 1 {
+		scale = float32(int(scale))
+	}
+	switch c.Mode {
+	case Stretch:
+		c.baseWidth, c.baseHeight = c.DesignWidth, c.DesignHeight
+	case Letterbox, Integer:
+		w, h := round(c.DesignWidth*scale), round(c.DesignHeight*scale)
+		c.Screen.X += (c.Screen.Width - w) / 2
+		c.Screen.Y += (c.Screen.Height - h) / 2
+		c.Screen.Width, c.Screen.Height = w, h
+		c.baseWidth, c.baseHeight = c.DesignWidth, c.DesignHeight
+	default:
+		if sw/c.DesignWidth < sh/c.DesignHeight {
+			c.baseWidth, c.baseHeight = c.DesignWidth, sh*c.DesignWidth/sw
+		} else {
+			c.baseWidth, c.baseHeight = sw*c.DesignHeight/sh, c.DesignHeight
+		}
+	}
+	c.World.Width, c.World.Height = c.baseWidth/zoom, c.baseHeight/zoom
+	if sized {
+		c.SetFocus(fx, fy)
+	}
+}
+
+func round(x float32) int {
+	return int(math.Floor(float64(x) + 0.5))
+}
+
+// View returns the matrix taking points in the world to the camera's frame, centred on the focus
+// and turned by the camera's rotation but still measured in world units
+func (c *Camera) View() Matrix {
+	x, y := c.Focus()
+	return Rotate(-c.Rotation).Mul(Translate(-x, -y))
+}
+
+// Projection returns the matrix taking points in the camera's frame to pixels on its screen,
+// measured from the top left corner of the screen
+func (c *Camera) Projection() Matrix {
+	sx, sy := float32(c.Screen.Width)/c.World.Width, float32(c.Screen.Height)/c.World.Height
+	return Translate(float32(c.Screen.Width)/2, float32(c.Screen.Height)/2).Mul(Scale(sx, sy))
+}
+
+// ViewProjection returns the matrix taking points in the world to pixels on the camera's screen,
+// measured from the top left corner of the screen, which is used to draw the world
+func (c *Camera) ViewProjection() Matrix {
+	return c.Projection().Mul(c.View())
+}
+
+// ScreenToWorld converts a point on the window, in pixels from its top left corner, to the point
+// in the world that the camera shows there
+func (c *Camera) ScreenToWorld(x, y float32) (wx, wy float32) {
+	return c.ViewProjection().Invert().Apply(x-float32(c.Screen.X), y-float32(c.Screen.Y))
+}
+
+// WorldToScreen converts a point in the world to the point on the window, in pixels from its top
+// left corner, where the camera shows it
+func (c *Camera) WorldToScreen(x, y float32) (sx, sy float32) {
+	sx, sy = c.ViewProjection().Apply(x, y)
+	return sx + float32(c.Screen.X), sy + float32(c.Screen.Y)
+}
+
+// Bounds returns the smallest rectangle containing all of the world that the camera shows, which
+// is the in-world rectangle unless the camera is rotated
+func (c *Camera) Bounds() Rectangle {
+	if c.Rotation == 0 {
+		return c.World
+	}
+	x, y := c.Focus()
+	ex, ey := c.extents()
+	return Rectangle{X: x - ex, Y: y - ey, Width: 2 * ex, Height: 2 * ey}
+}
+
+// Visible returns true if any of r lies within the camera's view, taking its rotation into
+// account
+func (c *Camera) Visible(r *Rectangle) bool {
+	if c.Rotation == 0 {
+		return c.World.Intersects(r)
+	}
+	// The view and r are separated if there is a gap between them along the axes of either.
+	x, y := c.Focus()
+	ex, ey := c.extents()
+	hw, hh := r.Width/2, r.Height/2
+	dx, dy := r.X+hw-x, r.Y+hh-y
+	if abs(dx) >= hw+ex || abs(dy) >= hh+ey {
+		return false
+	}
+	sin, cos := math.Sincos(float64(c.Rotation))
+	ux, uy := float32(cos), float32(sin)
+	if abs(dx*ux+dy*uy) >= c.World.Width/2+hw*abs(ux)+hh*abs(uy) {
+		return false
+	}
+	if abs(-dx*uy+dy*ux) >= c.World.Height/2+hw*abs(uy)+hh*abs(ux) {
+		return false
+	}
+	return true
+}
+
+// extents returns half the width and height of the bounds of the rotated view
+func (c *Camera) extents() (float32, float32) {
+	sin, cos := math.Sincos(float64(c.Rotation))
+	s, co := abs(float32(sin)), abs(float32(cos))
+	hw, hh := c.World.Width/2, c.World.Height/2
+	return co*hw + s*hh, s*hw + co*hh
+}
+
+func abs(x float32) float32 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Shows returns true if the camera's layer mask includes the numbered layer
+func (c *Camera) Shows(layer int) bool {
+	if c.Layers == 0 {
+		return true
+	}
+	return layer >= 0 && layer < 32 && c.Layers&(1<<uint(layer)) != 0
 }
 
 // Zoom performs a relative adjustment to the size of the in-world rectangle.
@@ -18,6 +240,24 @@ func (c *Camera) Zoom(relZoom float32) {
 	c.World.Y -= (c.World.Height - oh) / 2.0
 }
 
+// ZoomAt performs a relative adjustment to the size of the in-world rectangle, as for Zoom, while
+// keeping the in-world point (x,y) at the same place on screen, such as to zoom towards the mouse
+func (c *Camera) ZoomAt(relZoom, x, y float32) {
+	c.World.X = x - (x-c.World.X)/relZoom
+	c.World.Y = y - (y-c.World.Y)/relZoom
+	c.World.Width /= relZoom
+	c.World.Height /= relZoom
+}
+
+// Scale returns the number of screen pixels per unit of in-world width, or zero if either
+// rectangle has no width
+func (c *Camera) Scale() float32 {
+	if c.World.Width == 0 || c.Screen.Width == 0 {
+		return 0
+	}
+	return float32(c.Screen.Width) / c.World.Width
+}
+
 // Focus returns the coordinates of the centre of the in-world rectangle
 func (c *Camera) Focus() (x, y float32) {
 	x = c.World.X + c.World.Width/2.0
@@ -60,6 +300,8 @@ func (r *Rectangle) ContainsPoint(x, y float32) bool {
 	return false
 }
 
+// A Screen is a rectangle of the window in pixels, with X and Y giving its top left corner
 type Screen struct {
+	X, Y          int
 	Width, Height int
 }
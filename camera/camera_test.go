@@ -0,0 +1,118 @@
+package camera
+
+import (
+	"math"
+	"testing"
+)
+
+func TestViewport(t *testing.T) {
+	c := &Camera{Viewport: Rectangle{X: 0.25, Y: 0.5, Width: 0.5, Height: 0.5}}
+	c.Resize(100, 60)
+	if c.Screen != (Screen{X: 25, Y: 30, Width: 50, Height: 30}) {
+		t.Errorf("Wrong screen for viewport %v", c.Screen)
+	}
+	if !c.Shows(-1) || !c.Shows(40) {
+		t.Error("Expected camera with no layer mask to show every layer")
+	}
+	c.Layers = 1<<0 | 1<<3
+	if !c.Shows(0) || !c.Shows(3) || c.Shows(1) || c.Shows(-1) || c.Shows(32) {
+		t.Error("Wrong layers shown by mask")
+	}
+}
+
+func TestScaleModes(t *testing.T) {
+	tests := []struct {
+		mode          ScaleMode
+		width, height int
+		screen        Screen
+		world         Rectangle
+	}{
+		{Expand, 800, 500, Screen{0, 0, 800, 500}, Rectangle{-32, 0, 384, 240}},
+		{Stretch, 800, 500, Screen{0, 0, 800, 500}, Rectangle{0, 0, 320, 240}},
+		{Letterbox, 800, 500, Screen{66, 0, 667, 500}, Rectangle{0, 0, 320, 240}},
+		{Integer, 800, 500, Screen{80, 10, 640, 480}, Rectangle{0, 0, 320, 240}},
+		{Integer, 200, 200, Screen{0, 25, 200, 150}, Rectangle{0, 0, 320, 240}},
+	}
+	for _, test := range tests {
+		c := &Camera{World: Rectangle{Width: 320, Height: 240}, Mode: test.mode}
+		c.Resize(test.width, test.height)
+		if c.Screen != test.screen || c.World != test.world {
+			t.Errorf("Mode %v at %vx%v: expected screen %v and world %v, got %v and %v",
+				test.mode, test.width, test.height, test.screen, test.world, c.Screen, c.World)
+		}
+	}
+
+	c := &Camera{World: Rectangle{Width: 320, Height: 240}, Mode: Stretch}
+	c.Resize(640, 480)
+	c.Zoom(2)
+	c.Resize(1000, 500)
+	if c.World != (Rectangle{80, 60, 160, 120}) {
+		t.Errorf("Expected zoom to be kept after resizing, got %v", c.World)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	c := &Camera{World: Rectangle{X: 100, Y: 50, Width: 320, Height: 240}, Mode: Integer}
+	c.Resize(800, 500)
+	if x, y := c.WorldToScreen(100, 50); x != 80 || y != 10 {
+		t.Errorf("Expected top left of world at 80, 10, got %v, %v", x, y)
+	}
+	if x, y := c.WorldToScreen(420, 290); x != 720 || y != 490 {
+		t.Errorf("Expected bottom right of world at 720, 490, got %v, %v", x, y)
+	}
+	if x, y := c.ScreenToWorld(400, 250); x != 260 || y != 170 {
+		t.Errorf("Expected centre of screen at 260, 170, got %v, %v", x, y)
+	}
+}
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-3
+}
+
+func TestMatrix(t *testing.T) {
+	m := Translate(1, 2).Mul(Scale(2, 3))
+	if x, y := m.Apply(1, 1); x != 3 || y != 5 {
+		t.Errorf("Expected scaling then translation to give 3, 5, got %v, %v", x, y)
+	}
+	if x, y := m.ApplyVector(1, 1); x != 2 || y != 3 {
+		t.Errorf("Expected vector to ignore translation, got %v, %v", x, y)
+	}
+	m = m.Mul(Rotate(0.5))
+	if x, y := m.Invert().Mul(m).Apply(7, -4); !near(x, 7) || !near(y, -4) {
+		t.Errorf("Expected inverse to undo matrix, got %v, %v", x, y)
+	}
+	if x, y := Rotate(math.Pi/2).Apply(1, 0); !near(x, 0) || !near(y, 1) {
+		t.Errorf("Expected quarter turn to take 1, 0 to 0, 1, got %v, %v", x, y)
+	}
+	if Scale(0, 1).Invert() != Identity() {
+		t.Error("Expected singular matrix to invert to the identity")
+	}
+}
+
+func TestRotation(t *testing.T) {
+	c := &Camera{World: Rectangle{Width: 100, Height: 50}, Screen: Screen{Width: 200, Height: 100}, Rotation: math.Pi / 2}
+	x, y := c.WorldToScreen(60, 25)
+	if !near(x, 100) || !near(y, 30) {
+		t.Errorf("Expected point right of focus to appear above centre, got %v, %v", x, y)
+	}
+	if x, y = c.ScreenToWorld(x, y); !near(x, 60) || !near(y, 25) {
+		t.Errorf("Expected conversion to round trip, got %v, %v", x, y)
+	}
+	if b := c.Bounds(); !near(b.X, 25) || !near(b.Y, -25) || !near(b.Width, 50) || !near(b.Height, 100) {
+		t.Errorf("Wrong bounds for rotated view %v", b)
+	}
+
+	corner, above := Rectangle{0, 0, 10, 10}, Rectangle{45, -20, 10, 10}
+	if !c.Visible(&above) || c.Visible(&corner) {
+		t.Error("Expected rotated view to show the area above the world rectangle but not its corner")
+	}
+	c.Rotation = 0
+	if c.Visible(&above) || !c.Visible(&corner) {
+		t.Error("Expected unrotated view to show the world rectangle")
+	}
+	c.Rotation = math.Pi / 4
+	r := Rectangle{94, 69, 2, 2}
+	if b := c.Bounds(); !b.Contains(&r) || c.Visible(&r) {
+		t.Error("Expected rectangle in the corner of the bounds to be hidden")
+	}
+}
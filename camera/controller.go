@@ -0,0 +1,181 @@
+package camera
+
+import (
+	"math"
+	"time"
+
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/render"
+)
+
+// Controller is a system that moves a camera on each update: following a tagged entity, keeping
+// the camera's zoom and view within limits, and shaking it.
+type Controller struct {
+	Camera *Camera
+	// Target is the tag of the entity to follow, which must have a pos component. The camera does
+	// not follow anything if Target is empty or no entity has the tag.
+	Target string
+	// OffsetX and OffsetY are added to the target's position to give the point to follow, such
+	// as the centre of its sprite.
+	OffsetX, OffsetY float32
+	// DeadzoneWidth and DeadzoneHeight are the size of a rectangle at the centre of the view, in
+	// world units, within which the target can move without the camera following it.
+	DeadzoneWidth, DeadzoneHeight float32
+	// Smoothing is how long the camera takes to move about two thirds of the way to where it
+	// should be, easing into place rather than following the target rigidly. Zero follows
+	// immediately.
+	Smoothing time.Duration
+	// Bounds, if it has a non-zero size, is the area of the world, such as a level, that the
+	// camera is kept within. The camera is centred on the bounds if its view is larger.
+	Bounds Rectangle
+	// MinZoom and MaxZoom limit the camera's Scale, unless zero.
+	MinZoom, MaxZoom float32
+	// ShakeX and ShakeY are the furthest the camera moves from its place when shaking, in world
+	// units, and ShakeFrequency is roughly how many times a second it changes direction.
+	ShakeX, ShakeY float32
+	ShakeFrequency float32
+	// Recovery is how much trauma is lost per second.
+	Recovery float32
+	trauma   float32
+	shakeX   float32
+	shakeY   float32
+	time     float64
+}
+
+// NewController returns a controller for the camera, which shakes by up to 16 world units in
+// each direction around 15 times a second and recovers from full trauma in a second.
+func NewController(c *Camera) *Controller {
+	ctl := new(Controller)
+	ctl.Camera = c
+	ctl.ShakeX, ctl.ShakeY = 16, 16
+	ctl.ShakeFrequency = 15
+	ctl.Recovery = 1
+	return ctl
+}
+
+// Shake adds trauma to the camera, up to a maximum of one. The camera shakes in proportion to
+// the square of its trauma, so small knocks give a slight shake and heavy ones a violent one.
+func (ctl *Controller) Shake(trauma float32) {
+	ctl.trauma += trauma
+	if ctl.trauma > 1 {
+		ctl.trauma = 1
+	}
+	if ctl.trauma < 0 {
+		ctl.trauma = 0
+	}
+}
+
+// Trauma returns the camera's current trauma.
+func (ctl *Controller) Trauma() float32 {
+	return ctl.trauma
+}
+
+// Update moves the camera to follow its target, limits its zoom and position, and shakes it.
+func (ctl *Controller) Update(dt time.Duration, entities *entity.Manager) {
+	c := ctl.Camera
+	c.World.X -= ctl.shakeX
+	c.World.Y -= ctl.shakeY
+	ctl.shakeX, ctl.shakeY = 0, 0
+
+	if s := c.Scale(); s > 0 && ctl.MinZoom > 0 && s < ctl.MinZoom {
+		c.Zoom(ctl.MinZoom / s)
+	} else if s > 0 && ctl.MaxZoom > 0 && s > ctl.MaxZoom {
+		c.Zoom(ctl.MaxZoom / s)
+	}
+	ctl.follow(dt, entities)
+	ctl.clamp()
+
+	ctl.time += dt.Seconds()
+	ctl.Shake(-ctl.Recovery * float32(dt.Seconds()))
+	if ctl.trauma > 0 {
+		shake := ctl.trauma * ctl.trauma
+		t := ctl.time * float64(ctl.ShakeFrequency)
+		ctl.shakeX = ctl.ShakeX * shake * noise(0, t)
+		ctl.shakeY = ctl.ShakeY * shake * noise(1, t)
+		c.World.X += ctl.shakeX
+		c.World.Y += ctl.shakeY
+	}
+}
+
+// Draw does nothing.
+func (ctl *Controller) Draw(r render.Renderer, c *Camera, entities *entity.Manager) {}
+
+// follow moves the camera towards its target so that the target lies within the deadzone.
+func (ctl *Controller) follow(dt time.Duration, entities *entity.Manager) {
+	if ctl.Target == "" {
+		return
+	}
+	e := entities.Tag(ctl.Target)
+	if e == nil {
+		return
+	}
+	pos, ok := entity.Positions(entities).Get(e)
+	if !ok {
+		return
+	}
+	x, y := ctl.Camera.Focus()
+	tx, ty := pos.X+ctl.OffsetX, pos.Y+ctl.OffsetY
+	dx := outside(tx-x, ctl.DeadzoneWidth/2)
+	dy := outside(ty-y, ctl.DeadzoneHeight/2)
+	if ctl.Smoothing > 0 {
+		f := float32(1 - math.Exp(-dt.Seconds()/ctl.Smoothing.Seconds()))
+		dx *= f
+		dy *= f
+	}
+	ctl.Camera.SetFocus(x+dx, y+dy)
+}
+
+// outside returns how far d lies beyond the range from -r to r.
+func outside(d, r float32) float32 {
+	if d > r {
+		return d - r
+	}
+	if d < -r {
+		return d + r
+	}
+	return 0
+}
+
+// clamp keeps the camera's view within its bounds.
+func (ctl *Controller) clamp() {
+	b := ctl.Bounds
+	if b.Width == 0 || b.Height == 0 {
+		return
+	}
+	w := &ctl.Camera.World
+	w.X = clampAxis(w.X, w.Width, b.X, b.Width)
+	w.Y = clampAxis(w.Y, w.Height, b.Y, b.Height)
+}
+
+// clampAxis returns the start of a span of the given length moved to lie within the bounds, or
+// centred on them if it is longer.
+func clampAxis(x, length, min, bound float32) float32 {
+	if length >= bound {
+		return min + (bound-length)/2
+	}
+	if x < min {
+		return min
+	}
+	if x+length > min+bound {
+		return min + bound - length
+	}
+	return x
+}
+
+// noise returns smoothly varying pseudo-random values between -1 and 1 as t changes, with a
+// different sequence for each seed.
+func noise(seed uint32, t float64) float32 {
+	i := math.Floor(t)
+	f := float32(t - i)
+	a, b := lattice(seed, int64(i)), lattice(seed, int64(i)+1)
+	f = f * f * (3 - 2*f)
+	return a + (b-a)*f
+}
+
+// lattice returns a pseudo-random value between -1 and 1 for the integer i.
+func lattice(seed uint32, i int64) float32 {
+	h := uint32(i)*374761393 + seed*668265263
+	h = (h ^ h>>13) * 1274126177
+	h ^= h >> 16
+	return float32(h)/float32(math.MaxUint32)*2 - 1
+}
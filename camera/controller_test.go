@@ -0,0 +1,83 @@
+package camera
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FinnStokes/huge/entity"
+)
+
+func TestFollow(t *testing.T) {
+	entities := entity.NewManager()
+	player := entities.New()
+	entities.SetTag(player, "player")
+	pos := &entity.Position{X: 100, Y: 100}
+	entity.Positions(entities).Set(player, pos)
+
+	c := &Camera{World: Rectangle{X: 50, Y: 50, Width: 100, Height: 100}, Screen: Screen{Width: 200, Height: 200}}
+	ctl := NewController(c)
+	ctl.Target = "player"
+	ctl.DeadzoneWidth, ctl.DeadzoneHeight = 20, 20
+	ctl.Bounds = Rectangle{X: 0, Y: 0, Width: 300, Height: 200}
+
+	pos.X, pos.Y = 105, 95
+	ctl.Update(time.Second/60, entities)
+	if x, y := c.Focus(); x != 100 || y != 100 {
+		t.Errorf("Expected camera to stay put within the deadzone, got focus %v, %v", x, y)
+	}
+	pos.X, pos.Y = 130, 80
+	ctl.Update(time.Second/60, entities)
+	if x, y := c.Focus(); x != 120 || y != 90 {
+		t.Errorf("Expected target at edge of deadzone, got focus %v, %v", x, y)
+	}
+	pos.X, pos.Y = 400, 0
+	ctl.Update(time.Second/60, entities)
+	if c.World.X != 200 || c.World.Y != 0 {
+		t.Errorf("Expected camera to stop at the bounds, got %v", c.World)
+	}
+
+	ctl.Smoothing = time.Second
+	pos.X, pos.Y = 100, 60
+	ctl.Update(time.Second, entities)
+	if x, _ := c.Focus(); x < 161 || x > 162 {
+		t.Errorf("Expected camera to move two thirds of the way, got focus %v", x)
+	}
+}
+
+func TestZoom(t *testing.T) {
+	c := &Camera{World: Rectangle{X: 0, Y: 0, Width: 100, Height: 100}, Screen: Screen{Width: 100, Height: 100}}
+	c.ZoomAt(2, 20, 40)
+	if c.World != (Rectangle{X: 10, Y: 20, Width: 50, Height: 50}) {
+		t.Errorf("Wrong world after zooming at point %v", c.World)
+	}
+	ctl := NewController(c)
+	ctl.MaxZoom = 1.5
+	ctl.Update(time.Second/60, entity.NewManager())
+	if s := c.Scale(); s < 1.4999 || s > 1.5001 {
+		t.Errorf("Expected zoom to be limited to 1.5, got %v", s)
+	}
+}
+
+func TestShake(t *testing.T) {
+	c := &Camera{World: Rectangle{X: 0, Y: 0, Width: 100, Height: 100}, Screen: Screen{Width: 100, Height: 100}}
+	ctl := NewController(c)
+	ctl.Shake(2)
+	if ctl.Trauma() != 1 {
+		t.Errorf("Expected trauma to be limited to 1, got %v", ctl.Trauma())
+	}
+	moved := false
+	for i := 0; i < 60; i++ {
+		ctl.Update(time.Second/60, entity.NewManager())
+		if c.World.X < -16 || c.World.X > 16 || c.World.Y < -16 || c.World.Y > 16 {
+			t.Fatalf("Camera shook too far to %v", c.World)
+		}
+		moved = moved || c.World.X != 0
+	}
+	if !moved {
+		t.Error("Expected camera to shake")
+	}
+	ctl.Update(time.Second/60, entity.NewManager())
+	if ctl.Trauma() != 0 || c.World.X != 0 || c.World.Y != 0 {
+		t.Errorf("Expected camera to settle after a second, got trauma %v at %v", ctl.Trauma(), c.World)
+	}
+}
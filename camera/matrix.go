@@ -0,0 +1,71 @@
+package camera
+
+import "math"
+
+// A Matrix is an affine transformation of the plane, taking the point (x,y) to
+// (A*x + C*y + E, B*x + D*y + F)
+type Matrix struct {
+	A, B, C, D, E, F float32
+}
+
+// Identity returns the matrix that leaves every point where it is
+func Identity() Matrix {
+	return Matrix{A: 1, D: 1}
+}
+
+// Translate returns a matrix that moves points by (x,y)
+func Translate(x, y float32) Matrix {
+	return Matrix{A: 1, D: 1, E: x, F: y}
+}
+
+// Scale returns a matrix that stretches points away from the origin by x horizontally and y
+// vertically
+func Scale(x, y float32) Matrix {
+	return Matrix{A: x, D: y}
+}
+
+// Rotate returns a matrix that turns points about the origin by angle radians, which is
+// clockwise on screen as the y axis points down
+func Rotate(angle float32) Matrix {
+	sin, cos := math.Sincos(float64(angle))
+	return Matrix{A: float32(cos), B: float32(sin), C: -float32(sin), D: float32(cos)}
+}
+
+// Mul returns the matrix that applies n and then m
+func (m Matrix) Mul(n Matrix) Matrix {
+	return Matrix{
+		A: m.A*n.A + m.C*n.B,
+		B: m.B*n.A + m.D*n.B,
+		C: m.A*n.C + m.C*n.D,
+		D: m.B*n.C + m.D*n.D,
+		E: m.A*n.E + m.C*n.F + m.E,
+		F: m.B*n.E + m.D*n.F + m.F,
+	}
+}
+
+// Invert returns the matrix that undoes m, or the identity if m squashes the plane flat and so
+// cannot be undone
+func (m Matrix) Invert() Matrix {
+	det := m.A*m.D - m.B*m.C
+	if det == 0 {
+		return Identity()
+	}
+	return Matrix{
+		A: m.D / det,
+		B: -m.B / det,
+		C: -m.C / det,
+		D: m.A / det,
+		E: (m.C*m.F - m.D*m.E) / det,
+		F: (m.B*m.E - m.A*m.F) / det,
+	}
+}
+
+// Apply transforms the point (x,y)
+func (m Matrix) Apply(x, y float32) (float32, float32) {
+	return m.A*x + m.C*y + m.E, m.B*x + m.D*y + m.F
+}
+
+// ApplyVector transforms the offset (x,y) between two points, which is unaffected by translation
+func (m Matrix) ApplyVector(x, y float32) (float32, float32) {
+	return m.A*x + m.C*y, m.B*x + m.D*y
+}
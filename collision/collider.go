@@ -0,0 +1,253 @@
+// Package collision detects overlapping entities, using a spatial hash to find nearby pairs of
+// colliders and the separating axis test to check their shapes.
+package collision
+
+import (
+	"math"
+
+	"github.com/FinnStokes/huge/camera"
+	"github.com/FinnStokes/huge/entity"
+)
+
+// Shape is the kind of shape a collider has.
+type Shape int
+
+const (
+	// Box is an axis aligned rectangle with its top left corner at the collider's offset.
+	Box Shape = iota
+	// Circle is a circle centred on the collider's offset.
+	Circle
+	// Polygon is a convex polygon whose points are relative to the entity's position.
+	Polygon
+)
+
+// A Collider is a component giving the shape an entity occupies, relative to its position.
+type Collider struct {
+	Shape Shape
+	// X and Y offset the box or circle from the entity's position.
+	X, Y          float32
+	Width, Height float32
+	Radius        float32
+	Points        []Point
+	// Layer is the set of collision layers the collider belongs to, one per bit, and Mask is the
+	// set of layers it detects. A layer of zero is treated as the first layer, and a mask of zero
+	// as every layer.
+	Layer, Mask uint32
+	// Trigger colliders detect collisions without blocking movement, for things like pickups
+	// and checkpoints. Their contacts are marked as triggers, and physics bodies pass through
+	// their entities.
+	Trigger bool
+}
+
+// A Point is a position relative to an entity.
+type Point struct {
+	X, Y float32
+}
+
+// Colliders returns the entity manager's store of colliders, registered as "collider".
+func Colliders(m *entity.Manager) *entity.ComponentStore[*Collider] {
+	return entity.Register[*Collider](m, "collider")
+}
+
+// layer returns the collider's layers, applying the default.
+func (c *Collider) layer() uint32 {
+	if c.Layer == 0 {
+		return 1
+	}
+	return c.Layer
+}
+
+// Detects returns true if c's mask includes one of the other collider's layers.
+func (c *Collider) Detects(other *Collider) bool {
+	return c.Mask == 0 || c.Mask&other.layer() != 0
+}
+
+// Bounds returns the smallest rectangle containing the collider when its entity is at x, y.
+func (c *Collider) Bounds(x, y float32) camera.Rectangle {
+	switch c.Shape {
+	case Circle:
+		return camera.Rectangle{X: x + c.X - c.Radius, Y: y + c.Y - c.Radius, Width: 2 * c.Radius, Height: 2 * c.Radius}
+	case Polygon:
+		if len(c.Points) == 0 {
+			return camera.Rectangle{X: x, Y: y}
+		}
+		minX, minY, maxX, maxY := c.Points[0].X, c.Points[0].Y, c.Points[0].X, c.Points[0].Y
+		for _, p := range c.Points[1:] {
+			if p.X < minX {
+				minX = p.X
+			}
+			if p.Y < minY {
+				minY = p.Y
+			}
+			if p.X > maxX {
+				maxX = p.X
+			}
+			if p.Y > maxY {
+				maxY = p.Y
+			}
+		}
+		return camera.Rectangle{X: x + minX, Y: y + minY, Width: maxX - minX, Height: maxY - minY}
+	}
+	return camera.Rectangle{X: x + c.X, Y: y + c.Y, Width: c.Width, Height: c.Height}
+}
+
+// A Contact describes how two colliders overlap. The normal is a unit vector pointing from A
+// towards B, and Depth is how far B must move along it to separate them. Trigger is true if
+// either collider is a trigger, in which case the contact should not block movement.
+type Contact struct {
+	A, B             *entity.Entity
+	NormalX, NormalY float32
+	Depth            float32
+	Trigger          bool
+}
+
+// Collide tests whether collider a with its entity at ax, ay overlaps collider b with its entity
+// at bx, by, returning the contact if they do. Colliders that only touch do not overlap.
+func Collide(a *Collider, ax, ay float32, b *Collider, bx, by float32) (Contact, bool) {
+	if a.Shape == Circle && b.Shape == Circle {
+		return circles(ax+a.X, ay+a.Y, a.Radius, bx+b.X, by+b.Y, b.Radius)
+	}
+	if b.Shape == Circle {
+		return polygonCircle(a.polygon(ax, ay), bx+b.X, by+b.Y, b.Radius)
+	}
+	if a.Shape == Circle {
+		c, ok := polygonCircle(b.polygon(bx, by), ax+a.X, ay+a.Y, a.Radius)
+		c.NormalX, c.NormalY = -c.NormalX, -c.NormalY
+		return c, ok
+	}
+	return polygons(a.polygon(ax, ay), b.polygon(bx, by))
+}
+
+// polygon returns the points of a box or polygon collider in the world.
+func (c *Collider) polygon(x, y float32) []Point {
+	if c.Shape == Box {
+		x, y = x+c.X, y+c.Y
+		return []Point{{x, y}, {x + c.Width, y}, {x + c.Width, y + c.Height}, {x, y + c.Height}}
+	}
+	points := make([]Point, len(c.Points))
+	for i, p := range c.Points {
+		points[i] = Point{x + p.X, y + p.Y}
+	}
+	return points
+}
+
+func circles(ax, ay, ar, bx, by, br float32) (Contact, bool) {
+	dx, dy := bx-ax, by-ay
+	d := float32(math.Hypot(float64(dx), float64(dy)))
+	depth := ar + br - d
+	if depth <= 0 {
+		return Contact{}, false
+	}
+	if d == 0 {
+		return Contact{NormalX: 1, Depth: depth}, true
+	}
+	return Contact{NormalX: dx / d, NormalY: dy / d, Depth: depth}, true
+}
+
+// polygons applies the separating axis test to two convex polygons.
+func polygons(a, b []Point) (Contact, bool) {
+	if len(a) == 0 || len(b) == 0 {
+		return Contact{}, false
+	}
+	best := Contact{Depth: float32(math.Inf(1))}
+	for _, poly := range [][]Point{a, b} {
+		for i := range poly {
+			nx, ny := edgeNormal(poly[i], poly[(i+1)%len(poly)])
+			if nx == 0 && ny == 0 {
+				continue
+			}
+			if !overlap(&best, nx, ny, project(a, nx, ny), project(b, nx, ny)) {
+				return Contact{}, false
+			}
+		}
+	}
+	orient(&best, centre(a), centre(b))
+	return best, true
+}
+
+// polygonCircle applies the separating axis test to a convex polygon and a circle.
+func polygonCircle(a []Point, cx, cy, r float32) (Contact, bool) {
+	if len(a) == 0 {
+		return Contact{}, false
+	}
+	best := Contact{Depth: float32(math.Inf(1))}
+	circle := func(nx, ny float32) [2]float32 {
+		c := cx*nx + cy*ny
+		return [2]float32{c - r, c + r}
+	}
+	closest, distance := a[0], float32(math.Inf(1))
+	for i, p := range a {
+		nx, ny := edgeNormal(p, a[(i+1)%len(a)])
+		if (nx != 0 || ny != 0) && !overlap(&best, nx, ny, project(a, nx, ny), circle(nx, ny)) {
+			return Contact{}, false
+		}
+		if d := (p.X-cx)*(p.X-cx) + (p.Y-cy)*(p.Y-cy); d < distance {
+			closest, distance = p, d
+		}
+	}
+	if distance > 0 {
+		d := float32(math.Sqrt(float64(distance)))
+		nx, ny := (cx-closest.X)/d, (cy-closest.Y)/d
+		if !overlap(&best, nx, ny, project(a, nx, ny), circle(nx, ny)) {
+			return Contact{}, false
+		}
+	}
+	orient(&best, centre(a), Point{cx, cy})
+	return best, true
+}
+
+// edgeNormal returns the unit normal of the edge from p to q.
+func edgeNormal(p, q Point) (float32, float32) {
+	nx, ny := q.Y-p.Y, p.X-q.X
+	l := float32(math.Hypot(float64(nx), float64(ny)))
+	if l == 0 {
+		return 0, 0
+	}
+	return nx / l, ny / l
+}
+
+// project returns the interval that the points cover along the axis.
+func project(points []Point, nx, ny float32) [2]float32 {
+	min, max := float32(math.Inf(1)), float32(math.Inf(-1))
+	for _, p := range points {
+		d := p.X*nx + p.Y*ny
+		if d < min {
+			min = d
+		}
+		if d > max {
+			max = d
+		}
+	}
+	return [2]float32{min, max}
+}
+
+// overlap returns false if the intervals along the axis are separate, and otherwise keeps the
+// axis in c if it has the smallest overlap so far.
+func overlap(c *Contact, nx, ny float32, a, b [2]float32) bool {
+	depth := float32(math.Min(float64(a[1]-b[0]), float64(b[1]-a[0])))
+	if depth <= 0 {
+		return false
+	}
+	if depth < c.Depth {
+		c.NormalX, c.NormalY, c.Depth = nx, ny, depth
+	}
+	return true
+}
+
+// orient flips the contact normal if needed so that it points from a towards b.
+func orient(c *Contact, a, b Point) {
+	if (b.X-a.X)*c.NormalX+(b.Y-a.Y)*c.NormalY < 0 {
+		c.NormalX, c.NormalY = -c.NormalX, -c.NormalY
+	}
+}
+
+// centre returns the average of the points.
+func centre(points []Point) Point {
+	var c Point
+	for _, p := range points {
+		c.X += p.X
+		c.Y += p.Y
+	}
+	n := float32(len(points))
+	return Point{c.X / n, c.Y / n}
+}
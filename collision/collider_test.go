@@ -0,0 +1,85 @@
+package collision
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCollide(t *testing.T) {
+	box := &Collider{Shape: Box, Width: 10, Height: 10}
+	circle := &Collider{Shape: Circle, Radius: 5}
+	triangle := &Collider{Shape: Polygon, Points: []Point{{0, 0}, {10, 0}, {0, 10}}}
+	tests := []struct {
+		name          string
+		a             *Collider
+		ax, ay        float32
+		b             *Collider
+		bx, by        float32
+		ok            bool
+		nx, ny, depth float32
+	}{
+		{"boxes", box, 0, 0, box, 8, 1, true, 1, 0, 2},
+		{"boxes below", box, 0, 0, box, 1, 7, true, 0, 1, 3},
+		{"boxes reversed", box, 8, 1, box, 0, 0, true, -1, 0, 2},
+		{"boxes touching", box, 0, 0, box, 10, 0, false, 0, 0, 0},
+		{"boxes apart", box, 0, 0, box, 20, 20, false, 0, 0, 0},
+		{"circles", circle, 0, 0, circle, 0, 8, true, 0, 1, 2},
+		{"circles touching", circle, 0, 0, circle, 6, 8, false, 0, 0, 0},
+		{"box circle", box, 0, 0, circle, 13, 5, true, 1, 0, 2},
+		{"circle box", circle, 13, 5, box, 0, 0, true, -1, 0, 2},
+		{"box circle corner", box, 0, 0, circle, 14, 14, false, 0, 0, 0},
+		{"box circle diagonal", box, 0, 0, circle, 13, 14, false, 0, 0, 0},
+		{"box circle near corner", box, 0, 0, circle, 13, 13, true, float32(math.Sqrt2) / 2, float32(math.Sqrt2) / 2, 5 - 3*float32(math.Sqrt2)},
+		{"triangle box", triangle, 0, 0, box, 5, 5, false, 0, 0, 0},
+		{"triangle box overlap", triangle, 0, 0, box, 4, 4, true, float32(math.Sqrt2) / 2, float32(math.Sqrt2) / 2, float32(math.Sqrt2)},
+	}
+	for _, test := range tests {
+		c, ok := Collide(test.a, test.ax, test.ay, test.b, test.bx, test.by)
+		if ok != test.ok {
+			t.Errorf("%s: expected collision %v, got %v", test.name, test.ok, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if !near(c.NormalX, test.nx) || !near(c.NormalY, test.ny) {
+			t.Errorf("%s: expected normal (%v, %v), got (%v, %v)", test.name, test.nx, test.ny, c.NormalX, c.NormalY)
+		}
+		if test.depth != 0 && !near(c.Depth, test.depth) {
+			t.Errorf("%s: expected depth %v, got %v", test.name, test.depth, c.Depth)
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	circle := &Collider{Shape: Circle, X: 2, Radius: 3}
+	if b := circle.Bounds(10, 10); b.X != 9 || b.Y != 7 || b.Width != 6 || b.Height != 6 {
+		t.Errorf("Wrong circle bounds %v", b)
+	}
+	triangle := &Collider{Shape: Polygon, Points: []Point{{0, -4}, {5, 2}, {-3, 2}}}
+	if b := triangle.Bounds(10, 10); b.X != 7 || b.Y != 6 || b.Width != 8 || b.Height != 6 {
+		t.Errorf("Wrong polygon bounds %v", b)
+	}
+}
+
+func TestDetects(t *testing.T) {
+	player := &Collider{Layer: 1, Mask: 2 | 4}
+	wall := &Collider{Layer: 2}
+	pickup := &Collider{Layer: 8, Mask: 1, Trigger: true}
+	if !player.Detects(wall) || !wall.Detects(player) {
+		t.Error("Expected player and wall to detect each other")
+	}
+	if player.Detects(pickup) {
+		t.Error("Expected player not to detect pickup")
+	}
+	if !pickup.Detects(player) {
+		t.Error("Expected pickup to detect player")
+	}
+	if !pickup.Detects(&Collider{}) {
+		t.Error("Expected zero layer to be treated as the first layer")
+	}
+}
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-4
+}
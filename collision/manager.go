@@ -0,0 +1,219 @@
+package collision
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/FinnStokes/huge/camera"
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/render"
+)
+
+// Manager is a system that finds the entities with pos and collider components that overlap on
+// each update, and notifies its observers as collisions start, continue and end. A collision is
+// reported from the point of view of each collider whose mask detects the other, with the
+// detecting entity as the contact's A.
+type Manager struct {
+	// CellSize is the width and height of the cells of the spatial hash used to find colliders
+	// near each other. It works best at around the size of a typical collider. A size of zero or
+	// less is taken to be 64.
+	CellSize float32
+	cells    map[cell][]int
+	bodies   []body
+	contacts map[pair]Contact
+	previous map[pair]Contact
+	enter    []func(c Contact)
+	stay     []func(c Contact)
+	exit     []func(c Contact)
+}
+
+// cell is the coordinates of a cell of the spatial hash.
+type cell struct {
+	x, y int
+}
+
+// pair identifies a collision from the point of view of the detecting entity a.
+type pair struct {
+	a, b *entity.Entity
+}
+
+// body is a collider placed in the world for the current update.
+type body struct {
+	entity   *entity.Entity
+	collider *Collider
+	x, y     float32
+	bounds   camera.Rectangle
+}
+
+// defaultCellSize is the cell size of a new manager, used in place of invalid sizes.
+const defaultCellSize = 64
+
+// NewManager returns an initialised collision manager with 64 pixel cells.
+func NewManager() *Manager {
+	m := new(Manager)
+	m.CellSize = defaultCellSize
+	m.cells = make(map[cell][]int)
+	m.contacts = make(map[pair]Contact)
+	m.previous = make(map[pair]Contact)
+	return m
+}
+
+// OnEnter registers f to be called when two colliders start overlapping.
+func (m *Manager) OnEnter(f func(c Contact)) {
+	m.enter = append(m.enter, f)
+}
+
+// OnStay registers f to be called on each update after the first that two colliders overlap.
+func (m *Manager) OnStay(f func(c Contact)) {
+	m.stay = append(m.stay, f)
+}
+
+// OnExit registers f to be called when two colliders stop overlapping, including when one of
+// their entities is deleted or loses its collider. The contact has no normal or depth.
+func (m *Manager) OnExit(f func(c Contact)) {
+	m.exit = append(m.exit, f)
+}
+
+// Colliding returns true if a's collider detected b's at the last update.
+func (m *Manager) Colliding(a, b *entity.Entity) bool {
+	_, ok := m.contacts[pair{a, b}]
+	return ok
+}
+
+// Contacts returns the contacts detected by e at the last update.
+func (m *Manager) Contacts(e *entity.Entity) []Contact {
+	var contacts []Contact
+	for p, c := range m.contacts {
+		if p.a == e {
+			contacts = append(contacts, c)
+		}
+	}
+	sort.Slice(contacts, func(i, j int) bool { return contacts[i].B.Before(contacts[j].B) })
+	return contacts
+}
+
+// Update finds the colliders that overlap and notifies the observers. Observers are notified
+// once all collisions have been found, exits first, in the order the entities were created.
+func (m *Manager) Update(dt time.Duration, entities *entity.Manager) {
+	m.previous, m.contacts = m.contacts, m.previous
+	for p := range m.contacts {
+		delete(m.contacts, p)
+	}
+	m.build(entities)
+	for _, p := range m.candidates() {
+		a, b := &m.bodies[p[0]], &m.bodies[p[1]]
+		ab, ba := a.collider.Detects(b.collider), b.collider.Detects(a.collider)
+		if !ab && !ba {
+			continue
+		}
+		c, ok := Collide(a.collider, a.x, a.y, b.collider, b.x, b.y)
+		if !ok {
+			continue
+		}
+		c.Trigger = a.collider.Trigger || b.collider.Trigger
+		if ab {
+			c.A, c.B = a.entity, b.entity
+			m.contacts[pair{a.entity, b.entity}] = c
+		}
+		if ba {
+			m.contacts[pair{b.entity, a.entity}] = Contact{A: b.entity, B: a.entity, NormalX: -c.NormalX, NormalY: -c.NormalY, Depth: c.Depth, Trigger: c.Trigger}
+		}
+	}
+
+	var exits, enters, stays []Contact
+	for p := range m.previous {
+		if _, ok := m.contacts[p]; !ok {
+			exits = append(exits, Contact{A: p.a, B: p.b})
+		}
+	}
+	for p, c := range m.contacts {
+		if _, ok := m.previous[p]; ok {
+			stays = append(stays, c)
+		} else {
+			enters = append(enters, c)
+		}
+	}
+	notify(m.exit, exits)
+	notify(m.enter, enters)
+	notify(m.stay, stays)
+}
+
+// Draw does nothing.
+func (m *Manager) Draw(r render.Renderer, c *camera.Camera, entities *entity.Manager) {}
+
+// build places every collider in the spatial hash.
+func (m *Manager) build(entities *entity.Manager) {
+	for k := range m.cells {
+		delete(m.cells, k)
+	}
+	m.bodies = m.bodies[:0]
+	colliders := Colliders(entities)
+	positions := entity.Positions(entities)
+	for _, e := range entities.View(entity.With("pos", "collider")).Entities() {
+		c, _ := colliders.Get(e)
+		pos, _ := positions.Get(e)
+		b := body{entity: e, collider: c, x: pos.X, y: pos.Y, bounds: c.Bounds(pos.X, pos.Y)}
+		m.bodies = append(m.bodies, b)
+		minX, minY := m.cell(b.bounds.X, b.bounds.Y)
+		maxX, maxY := m.cell(b.bounds.X+b.bounds.Width, b.bounds.Y+b.bounds.Height)
+		for y := minY; y <= maxY; y++ {
+			for x := minX; x <= maxX; x++ {
+				k := cell{x, y}
+				m.cells[k] = append(m.cells[k], len(m.bodies)-1)
+			}
+		}
+	}
+}
+
+func (m *Manager) cell(x, y float32) (int, int) {
+	size := m.CellSize
+	if size <= 0 {
+		size = defaultCellSize
+	}
+	return int(math.Floor(float64(x / size))), int(math.Floor(float64(y / size)))
+}
+
+// candidates returns the pairs of bodies sharing a cell whose bounds intersect, in a stable
+// order.
+func (m *Manager) candidates() [][2]int {
+	seen := make(map[[2]int]bool)
+	var pairs [][2]int
+	for _, indices := range m.cells {
+		for i, a := range indices {
+			for _, b := range indices[i+1:] {
+				p := [2]int{a, b}
+				if seen[p] {
+					continue
+				}
+				seen[p] = true
+				if m.bodies[a].bounds.Intersects(&m.bodies[b].bounds) {
+					pairs = append(pairs, p)
+				}
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	return pairs
+}
+
+// notify sorts the contacts into the order their entities were created and passes each to the
+// observers.
+func notify(observers []func(c Contact), contacts []Contact) {
+	sort.Slice(contacts, func(i, j int) bool {
+		if contacts[i].A != contacts[j].A {
+			return contacts[i].A.Before(contacts[j].A)
+		}
+		return contacts[i].B.Before(contacts[j].B)
+	})
+	for _, c := range contacts {
+		for _, f := range observers {
+			f(c)
+		}
+	}
+}
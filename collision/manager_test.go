@@ -0,0 +1,82 @@
+package collision
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/FinnStokes/huge/entity"
+)
+
+func TestManager(t *testing.T) {
+	entities := entity.NewManager()
+	positions := entity.Positions(entities)
+	colliders := Colliders(entities)
+	names := make(map[*entity.Entity]string)
+	add := func(name string, x, y float32, c *Collider) *entity.Entity {
+		e := entities.New()
+		positions.Set(e, &entity.Position{X: x, Y: y})
+		colliders.Set(e, c)
+		names[e] = name
+		return e
+	}
+	player := add("player", 0, 0, &Collider{Width: 10, Height: 10, Layer: 1, Mask: 2})
+	wall := add("wall", 100, 0, &Collider{Width: 10, Height: 100, Layer: 2, Mask: 1})
+	coin := add("coin", 200, 5, &Collider{Shape: Circle, Radius: 2, Layer: 4, Mask: 1, Trigger: true})
+	add("ghost", 100, 0, &Collider{Width: 10, Height: 10, Layer: 16, Mask: 16})
+
+	m := NewManager()
+	var events []string
+	record := func(kind string) func(c Contact) {
+		return func(c Contact) { events = append(events, fmt.Sprintf("%s %s %s", kind, names[c.A], names[c.B])) }
+	}
+	m.OnEnter(record("enter"))
+	m.OnStay(record("stay"))
+	m.OnExit(record("exit"))
+
+	step := func(x float32, expected ...string) {
+		t.Helper()
+		pos, _ := positions.Get(player)
+		pos.X = x
+		events = nil
+		m.Update(time.Second/60, entities)
+		if !reflect.DeepEqual(events, expected) {
+			t.Errorf("At %v: expected events %q, got %q", x, expected, events)
+		}
+	}
+	step(0)
+	step(95, "enter player wall", "enter wall player")
+	if !m.Colliding(player, wall) || m.Colliding(player, coin) {
+		t.Error("Wrong collisions after entering wall")
+	}
+	if c := m.Contacts(player); len(c) != 1 || c[0].B != wall || c[0].NormalX != 1 || c[0].Depth != 5 || c[0].Trigger {
+		t.Errorf("Wrong contacts %v", c)
+	}
+	step(96, "stay player wall", "stay wall player")
+	step(195, "exit player wall", "exit wall player", "enter coin player")
+	if c := m.Contacts(coin); len(c) != 1 || !c[0].Trigger {
+		t.Errorf("Contacts %v with a trigger are not marked as triggers", c)
+	}
+	entities.Delete(coin)
+	step(195, "exit coin player")
+}
+
+func TestCellSize(t *testing.T) {
+	entities := entity.NewManager()
+	var es []*entity.Entity
+	for _, x := range []float32{0, 5} {
+		e := entities.New()
+		entity.Positions(entities).Set(e, &entity.Position{X: x})
+		Colliders(entities).Set(e, &Collider{Width: 10, Height: 10})
+		es = append(es, e)
+	}
+	for _, size := range []float32{0, -8} {
+		m := NewManager()
+		m.CellSize = size
+		m.Update(time.Second/60, entities)
+		if !m.Colliding(es[0], es[1]) {
+			t.Errorf("Colliders not found with a cell size of %v", size)
+		}
+	}
+}
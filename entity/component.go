@@ -0,0 +1,184 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// A ComponentStore holds every component of a single type, keyed by the entity that owns it.
+// Components are packed densely, so iterating over a store only visits entities that have
+// that component.
+type ComponentStore[T any] struct {
+	name       string
+	manager    *Manager
+	components []T
+	entities   []*Entity
+	index      map[*Entity]int
+	codec      Codec[T]
+}
+
+// store is the type-independent view of a ComponentStore used by the manager.
+type store interface {
+	has(e *Entity) bool
+	remove(e *Entity)
+	marshal(e *Entity) ([]byte, error)
+	unmarshal(data []byte) (func(e *Entity), error)
+}
+
+// A Codec converts components of a single type to and from JSON, so that they can be saved
+// and loaded.
+type Codec[T any] interface {
+	Marshal(c T) ([]byte, error)
+	Unmarshal(data []byte) (T, error)
+}
+
+// jsonCodec is the default codec, which uses the standard JSON encoding of the component type.
+type jsonCodec[T any] struct{}
+
+func (jsonCodec[T]) Marshal(c T) ([]byte, error) {
+	return json.Marshal(c)
+}
+
+func (jsonCodec[T]) Unmarshal(data []byte) (c T, err error) {
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
+
+// Register returns the manager's store for the named component type, creating it if it does
+// not yet exist. It panics if the name has already been registered with a different type.
+func Register[T any](m *Manager, name string) *ComponentStore[T] {
+	if s, ok := m.stores[name]; ok {
+		typed, ok := s.(*ComponentStore[T])
+		if !ok {
+			var c T
+			panic(fmt.Sprintf("entity: component %q already registered with a type other than %T", name, c))
+		}
+		return typed
+	}
+	s := &ComponentStore[T]{
+		name:    name,
+		manager: m,
+		index:   make(map[*Entity]int),
+		codec:   jsonCodec[T]{},
+	}
+	m.stores[name] = s
+	i := sort.SearchStrings(m.names, name)
+	m.names = append(m.names, "")
+	copy(m.names[i+1:], m.names[i:])
+	m.names[i] = name
+	return s
+}
+
+// Name returns the name the component type was registered with.
+func (s *ComponentStore[T]) Name() string {
+	return s.name
+}
+
+// SetCodec sets the codec used to save and load components of this type, replacing the default
+// codec which uses the standard JSON encoding of the type.
+func (s *ComponentStore[T]) SetCodec(c Codec[T]) {
+	s.codec = c
+}
+
+// Get returns the component belonging to the given entity, and whether it has one.
+func (s *ComponentStore[T]) Get(e *Entity) (c T, ok bool) {
+	i, ok := s.index[e]
+	if ok {
+		c = s.components[i]
+	}
+	return c, ok
+}
+
+// Has returns true if the given entity has a component of this type.
+func (s *ComponentStore[T]) Has(e *Entity) bool {
+	_, ok := s.index[e]
+	return ok
+}
+
+// Set gives the entity the component c, replacing any it already had. Setting a component on
+// a deleted entity has no effect. Replacing a component notifies the removal observers for the
+// old component followed by the addition observers for the new one.
+func (s *ComponentStore[T]) Set(e *Entity, c T) {
+	if i, ok := s.index[e]; ok {
+		notify(s.manager.observers.componentRemoved[s.name], e)
+		s.components[i] = c
+		notify(s.manager.observers.componentAdded[s.name], e)
+		return
+	}
+	if s.manager.Get(e.id) != e {
+		return
+	}
+	s.index[e] = len(s.components)
+	s.components = append(s.components, c)
+	s.entities = append(s.entities, e)
+	s.manager.refresh(e, s.name)
+	notify(s.manager.observers.componentAdded[s.name], e)
+}
+
+// Remove removes the entity's component, if it has one, after notifying the removal observers.
+func (s *ComponentStore[T]) Remove(e *Entity) {
+	if !s.Has(e) {
+		return
+	}
+	notify(s.manager.observers.componentRemoved[s.name], e)
+	// The observers may have removed or replaced the component themselves.
+	i, ok := s.index[e]
+	if !ok {
+		return
+	}
+	last := len(s.components) - 1
+	s.components[i] = s.components[last]
+	s.entities[i] = s.entities[last]
+	s.index[s.entities[i]] = i
+	var zero T
+	s.components[last] = zero
+	s.entities[last] = nil
+	s.components = s.components[:last]
+	s.entities = s.entities[:last]
+	delete(s.index, e)
+	s.manager.refresh(e, s.name)
+}
+
+// Len returns the number of entities with a component of this type.
+func (s *ComponentStore[T]) Len() int {
+	return len(s.components)
+}
+
+// Entities returns the entities with a component of this type, in the same order as the
+// slice returned by Components. The slice is owned by the store and must not be modified, and
+// is invalidated by any call to Set or Remove.
+func (s *ComponentStore[T]) Entities() []*Entity {
+	return s.entities
+}
+
+// Components returns all components of this type, in the same order as the slice returned by
+// Entities. The slice is owned by the store and is invalidated by any call to Set or Remove.
+func (s *ComponentStore[T]) Components() []T {
+	return s.components
+}
+
+func (s *ComponentStore[T]) has(e *Entity) bool {
+	return s.Has(e)
+}
+
+func (s *ComponentStore[T]) remove(e *Entity) {
+	s.Remove(e)
+}
+
+func (s *ComponentStore[T]) marshal(e *Entity) ([]byte, error) {
+	c, ok := s.Get(e)
+	if !ok {
+		return nil, nil
+	}
+	return s.codec.Marshal(c)
+}
+
+// unmarshal decodes a component, returning a function that gives it to an entity.
+func (s *ComponentStore[T]) unmarshal(data []byte) (func(e *Entity), error) {
+	c, err := s.codec.Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("entity: decoding component %q: %v", s.name, err)
+	}
+	return func(e *Entity) { s.Set(e, c) }, nil
+}
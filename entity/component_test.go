@@ -0,0 +1,75 @@
+package entity
+
+import "testing"
+
+type velocity struct {
+	X, Y float32
+}
+
+func TestComponents(t *testing.T) {
+	m := NewManager()
+	positions := Positions(m)
+	velocities := Register[velocity](m, "vel")
+	entities := make([]*Entity, 100)
+	for i := range entities {
+		entities[i] = m.New()
+		positions.Set(entities[i], &Position{float32(i), 0})
+		if i%3 == 0 {
+			velocities.Set(entities[i], velocity{0, float32(i)})
+		}
+	}
+	if positions.Len() != 100 || velocities.Len() != 34 {
+		t.Errorf("Wrong number of components (%v positions, %v velocities)", positions.Len(), velocities.Len())
+	}
+	if Positions(m) != positions || Register[velocity](m, "vel") != velocities {
+		t.Errorf("Registering an existing component did not return the existing store")
+	}
+	for i, e := range velocities.Entities() {
+		v := velocities.Components()[i]
+		if int(v.Y)%3 != 0 || entities[int(v.Y)] != e {
+			t.Errorf("Velocity %v does not match entity %v", v, e.Id())
+		}
+	}
+	for i, e := range entities {
+		v, ok := velocities.Get(e)
+		if ok != (i%3 == 0) || ok != m.HasComponent(e, "vel") {
+			t.Errorf("Entity %v has velocity %v", i, ok)
+		} else if ok && v.Y != float32(i) {
+			t.Errorf("Entity %v velocity does not match (%v)", i, v)
+		}
+		if i%2 == 0 {
+			velocities.Remove(e)
+		}
+	}
+	for i, e := range entities {
+		if _, ok := velocities.Get(e); ok != (i%3 == 0 && i%2 != 0) {
+			t.Errorf("Entity %v has velocity %v", i, ok)
+		}
+	}
+	velocities.Set(entities[3], velocity{1, 1})
+	if v, _ := velocities.Get(entities[3]); v != (velocity{1, 1}) {
+		t.Errorf("Replaced velocity does not match (%v)", v)
+	}
+	for _, e := range entities[:50] {
+		m.Delete(e)
+	}
+	if positions.Len() != 50 || velocities.Len() != 9 {
+		t.Errorf("Components not removed with entities (%v positions, %v velocities)", positions.Len(), velocities.Len())
+	}
+	for _, e := range positions.Entities() {
+		if e.Id().Index() < 50 {
+			t.Errorf("Entity %v still has a position", e.Id())
+		}
+	}
+}
+
+func TestRegisterMismatch(t *testing.T) {
+	m := NewManager()
+	Register[velocity](m, "vel")
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Registering a component with a different type did not panic")
+		}
+	}()
+	Register[*velocity](m, "vel")
+}
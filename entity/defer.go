@@ -0,0 +1,49 @@
+package entity
+
+// command is a deferred change to a manager's entities.
+type command func(m *Manager)
+
+// DeferNew schedules the creation of an entity at the next call to Flush. Once created, the
+// entity is passed to init, if it is not nil, so that components, tags and groups can be set.
+func (m *Manager) DeferNew(init func(e *Entity)) {
+	m.commands = append(m.commands, func(m *Manager) {
+		e := m.New()
+		if init != nil {
+			init(e)
+		}
+	})
+}
+
+// DeferDelete schedules the deletion of the given entity at the next call to Flush.
+func (m *Manager) DeferDelete(entity *Entity) {
+	m.commands = append(m.commands, func(m *Manager) {
+		m.Delete(entity)
+	})
+}
+
+// DeferSetGroup schedules the given entity to be added to the given group(s) at the next call to
+// Flush.
+func (m *Manager) DeferSetGroup(entity *Entity, groups ...string) {
+	m.commands = append(m.commands, func(m *Manager) {
+		m.SetGroup(entity, groups...)
+	})
+}
+
+// Flush applies all deferred changes in the order they were scheduled. Changes scheduled while
+// flushing, such as by the init function passed to DeferNew, are applied after those already
+// pending, before Flush returns. Changes to an entity that has been deleted by the time they are
+// applied have no effect.
+func (m *Manager) Flush() {
+	for len(m.commands) > 0 {
+		c := m.commands[0]
+		m.commands[0] = nil
+		m.commands = m.commands[1:]
+		c(m)
+	}
+	m.commands = nil
+}
+
+// Pending returns the number of deferred changes waiting to be applied.
+func (m *Manager) Pending() int {
+	return len(m.commands)
+}
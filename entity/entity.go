@@ -1,19 +1,54 @@
 // Package entity implements representation of in-game objects as entities.
 package entity
 
-// Entity is a type to represent an object in the game world. It uses components to describe
-// its form and function.
+import "container/list"
+
+// Entity is a type to represent an object in the game world. It uses components, held in
+// ComponentStores on the entity's manager, to describe its form and function.
 type Entity struct {
-	id         int
-	Components map[string]interface{}
+	id     Id
+	elem   *list.Element
+	order  uint64
+	tags   map[string]bool
+	groups map[string]bool
 }
 
-// Id returns the integral id of the entity
-func (e *Entity) Id() int {
+// Id returns the id of the entity
+func (e *Entity) Id() Id {
 	return e.id
 }
 
+// Before returns true if e was added to its manager before other. Unlike the order of views, this
+// does not change as other entities are deleted, so it can be used to sort entities stably.
+func (e *Entity) Before(other *Entity) bool {
+	return e.order < other.order
+}
+
+// An Id identifies an entity within its manager. Ids are made up of an index, which is reused
+// once the entity is deleted, and a generation, which is incremented each time the index is
+// reused, so that the id of a deleted entity never refers to a live one.
+type Id uint64
+
+func makeId(index, generation uint32) Id {
+	return Id(generation)<<32 | Id(index)
+}
+
+// Index returns the index part of the id, which is unique among live entities.
+func (id Id) Index() int {
+	return int(uint32(id))
+}
+
+// Generation returns the number of times the id's index had been reused when it was assigned.
+func (id Id) Generation() int {
+	return int(uint32(id >> 32))
+}
+
 // Position is a type used as a component for storing a position in 2d coordinates
 type Position struct {
 	X, Y float32
 }
+
+// Positions returns the manager's store of position components, registered as "pos".
+func Positions(m *Manager) *ComponentStore[*Position] {
+	return Register[*Position](m, "pos")
+}
@@ -0,0 +1,100 @@
+package entity
+
+// observers holds the functions to be notified of changes to a manager's entities.
+type observers struct {
+	create           observerList[func(e *Entity)]
+	delete           observerList[func(e *Entity)]
+	componentAdded   map[string]*observerList[func(e *Entity)]
+	componentRemoved map[string]*observerList[func(e *Entity)]
+	tagged           observerList[func(e *Entity, tag string)]
+	groupJoined      map[string]*observerList[func(e *Entity)]
+	groupLeft        map[string]*observerList[func(e *Entity)]
+}
+
+// observerList holds observer functions in the order they were registered.
+type observerList[F any] struct {
+	fs []*F
+}
+
+// add appends f to the list and returns a function that removes it again.
+func (l *observerList[F]) add(f F) func() {
+	p := &f
+	l.fs = append(l.fs, p)
+	return func() {
+		for i, q := range l.fs {
+			if q == p {
+				// Copy rather than shift in place, so that a notification in progress still
+				// sees the list it started with.
+				fs := make([]*F, 0, len(l.fs)-1)
+				l.fs = append(append(fs, l.fs[:i]...), l.fs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// named returns the list in lists for the given name, creating it if necessary.
+func named(lists *map[string]*observerList[func(e *Entity)], name string) *observerList[func(e *Entity)] {
+	if *lists == nil {
+		*lists = make(map[string]*observerList[func(e *Entity)])
+	}
+	l, ok := (*lists)[name]
+	if !ok {
+		l = new(observerList[func(e *Entity)])
+		(*lists)[name] = l
+	}
+	return l
+}
+
+// OnCreate registers f to be called whenever an entity is created. It returns a function that
+// unregisters f.
+func (m *Manager) OnCreate(f func(e *Entity)) (cancel func()) {
+	return m.observers.create.add(f)
+}
+
+// OnDelete registers f to be called whenever an entity is deleted. It is called once the entity
+// is no longer alive, but before any of its components, tags or groups are removed, so that they
+// can still be inspected. It returns a function that unregisters f.
+func (m *Manager) OnDelete(f func(e *Entity)) (cancel func()) {
+	return m.observers.delete.add(f)
+}
+
+// OnComponentAdded registers f to be called whenever an entity is given the named component.
+// It is called after the component is added. It returns a function that unregisters f.
+func (m *Manager) OnComponentAdded(name string, f func(e *Entity)) (cancel func()) {
+	return named(&m.observers.componentAdded, name).add(f)
+}
+
+// OnComponentRemoved registers f to be called whenever the named component is removed from an
+// entity, including when the entity is deleted. It is called before the component is removed,
+// so that it can still be retrieved. It returns a function that unregisters f.
+func (m *Manager) OnComponentRemoved(name string, f func(e *Entity)) (cancel func()) {
+	return named(&m.observers.componentRemoved, name).add(f)
+}
+
+// OnTagged registers f to be called whenever a tag is set to refer to an entity. It returns a
+// function that unregisters f.
+func (m *Manager) OnTagged(f func(e *Entity, tag string)) (cancel func()) {
+	return m.observers.tagged.add(f)
+}
+
+// OnGroupJoined registers f to be called whenever an entity joins the named group. It returns
+// a function that unregisters f.
+func (m *Manager) OnGroupJoined(group string, f func(e *Entity)) (cancel func()) {
+	return named(&m.observers.groupJoined, group).add(f)
+}
+
+// OnGroupLeft registers f to be called whenever an entity leaves the named group, including
+// when the entity is deleted. It returns a function that unregisters f.
+func (m *Manager) OnGroupLeft(group string, f func(e *Entity)) (cancel func()) {
+	return named(&m.observers.groupLeft, group).add(f)
+}
+
+func notify(l *observerList[func(e *Entity)], e *Entity) {
+	if l == nil {
+		return
+	}
+	for _, f := range l.fs {
+		(*f)(e)
+	}
+}
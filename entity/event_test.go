@@ -0,0 +1,133 @@
+package entity
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEvents(t *testing.T) {
+	m := NewManager()
+	positions := Positions(m)
+	var events []string
+	record := func(event string) func(e *Entity) {
+		return func(e *Entity) {
+			events = append(events, fmt.Sprintf("%v %v", event, e.Id().Index()))
+		}
+	}
+	m.OnCreate(record("create"))
+	m.OnDelete(func(e *Entity) {
+		if _, ok := positions.Get(e); !ok || !m.InGroup(e, "a") {
+			t.Errorf("Entity %v stripped before delete observers were notified", e.Id())
+		}
+		record("delete")(e)
+	})
+	m.OnComponentAdded("pos", record("add pos"))
+	m.OnComponentRemoved("pos", func(e *Entity) {
+		if _, ok := positions.Get(e); !ok {
+			t.Errorf("Position of %v removed before observers were notified", e.Id())
+		}
+		record("remove pos")(e)
+	})
+	m.OnTagged(func(e *Entity, tag string) {
+		record("tag " + tag)(e)
+	})
+	m.OnGroupJoined("a", record("join a"))
+	m.OnGroupLeft("a", record("leave a"))
+
+	e0 := m.New()
+	e1 := m.New()
+	positions.Set(e0, &Position{})
+	positions.Set(e0, &Position{1, 1})
+	m.SetTag(e1, "player")
+	m.SetGroup(e1, "a", "b")
+	m.SetGroup(e1, "a")
+	m.ClearGroup(e1, "a")
+	positions.Remove(e0)
+	positions.Remove(e0)
+	positions.Set(e1, &Position{})
+	m.SetGroup(e1, "a")
+	m.Delete(e1)
+	m.Delete(e1)
+
+	expected := []string{
+		"create 0", "create 1",
+		"add pos 0", "remove pos 0", "add pos 0",
+		"tag player 1",
+		"join a 1", "leave a 1",
+		"remove pos 0",
+		"add pos 1", "join a 1",
+		"delete 1", "remove pos 1", "leave a 1",
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Events do not match:\n%v\nvs\n%v", events, expected)
+	}
+	for i := range events {
+		if events[i] != expected[i] {
+			t.Errorf("Event %v does not match (%v vs %v)", i, events[i], expected[i])
+		}
+	}
+}
+
+func TestCancel(t *testing.T) {
+	m := NewManager()
+	positions := Positions(m)
+	var added []int
+	var cancels []func()
+	for i := 0; i < 3; i++ {
+		i := i
+		cancels = append(cancels, m.OnComponentAdded("pos", func(e *Entity) {
+			added = append(added, i)
+		}))
+	}
+	cancelCreate := m.OnCreate(func(e *Entity) {
+		t.Errorf("Cancelled create observer called for %v", e.Id())
+	})
+	cancelCreate()
+	cancels[1]()
+	cancels[1]()
+	positions.Set(m.New(), &Position{})
+	if len(added) != 2 || added[0] != 0 || added[1] != 2 {
+		t.Errorf("Remaining observers called as %v, expected [0 2]", added)
+	}
+	cancels[0]()
+	cancels[2]()
+	if n := len(m.observers.componentAdded["pos"].fs); n != 0 {
+		t.Errorf("%v observers left after cancelling all of them", n)
+	}
+}
+
+func TestDeleteOrder(t *testing.T) {
+	m := NewManager()
+	var events []string
+	for _, name := range []string{"c", "a", "b"} {
+		name := name
+		Register[int](m, name).Set(m.New(), 0)
+		m.OnComponentRemoved(name, func(e *Entity) {
+			events = append(events, "remove "+name)
+		})
+	}
+	e := m.New()
+	for _, name := range []string{"b", "c", "a"} {
+		Register[int](m, name).Set(e, 1)
+	}
+	m.SetGroup(e, "y", "x")
+	for _, g := range []string{"x", "y"} {
+		g := g
+		m.OnGroupLeft(g, func(e *Entity) {
+			events = append(events, "leave "+g)
+		})
+	}
+	m.OnDelete(func(d *Entity) {
+		if m.IsAlive(d.Id()) {
+			t.Errorf("Entity %v still alive when delete observers were notified", d.Id())
+		}
+		events = append(events, "delete")
+		m.Delete(d)
+	})
+	m.Delete(e)
+
+	expected := []string{"delete", "remove a", "remove b", "remove c", "leave x", "leave y"}
+	if fmt.Sprint(events) != fmt.Sprint(expected) {
+		t.Errorf("Events do not match:\n%v\nvs\n%v", events, expected)
+	}
+}
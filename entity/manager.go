@@ -6,18 +6,31 @@ import "container/list"
 // to them by id, tag and group.
 type Manager struct {
 	entities list.List
-	nextId   int
-	ids      map[int]*Entity
+	slots    []slot
+	free     []uint32
 	tags     map[string]*Entity
 	groups   map[string]map[*Entity]bool
+	stores   map[string]store
+	names    []string
+	views    map[string]*View
+	commands []command
+	added    uint64
+	observers
+}
+
+// slot holds the entity currently assigned an index, along with the generation of that index.
+type slot struct {
+	entity     *Entity
+	generation uint32
 }
 
 // NewManager returns an initialised entity manager.
 func NewManager() *Manager {
 	m := new(Manager)
-	m.ids = make(map[int]*Entity)
 	m.tags = make(map[string]*Entity)
 	m.groups = make(map[string]map[*Entity]bool)
+	m.stores = make(map[string]store)
+	m.views = make(map[string]*View)
 	return m
 }
 
@@ -32,9 +45,20 @@ func (m *Manager) All() []*Entity {
 	return entities
 }
 
-// Get returns the unique entity with the given id.
-func (m *Manager) Get(id int) *Entity {
-	return m.ids[id]
+// Get returns the unique entity with the given id, or nil if it has been deleted.
+func (m *Manager) Get(id Id) *Entity {
+	i := id.Index()
+	if i < len(m.slots) {
+		if e := m.slots[i].entity; e != nil && e.id == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// IsAlive returns true if the entity with the given id exists and has not been deleted.
+func (m *Manager) IsAlive(id Id) bool {
+	return m.Get(id) != nil
 }
 
 // Tag returns the unique entity with the given tag.
@@ -54,76 +78,147 @@ func (m *Manager) Group(id string) []*Entity {
 
 // InGroup returns true if the given entity is in the named group and false otherwise.
 func (m *Manager) InGroup(entity *Entity, id string) bool {
-	g, ok := m.groups[id]
-	in := false
-	if ok {
-		_, in = g[entity]
-	}
-	return in
+	return entity.groups[id]
 }
 
 // New creates a new entity, assigning it to the next available id and
 // returning the created entity.
 func (m *Manager) New() *Entity {
+	var index uint32
+	if n := len(m.free); n > 0 {
+		index = m.free[n-1]
+		m.free = m.free[:n-1]
+	} else {
+		index = uint32(len(m.slots))
+		m.slots = append(m.slots, slot{})
+	}
 	e := new(Entity)
-	e.id = m.nextId
-	e.Components = make(map[string]interface{})
-	m.nextId++
-	m.entities.PushBack(e)
-	m.ids[e.id] = e
+	e.id = makeId(index, m.slots[index].generation)
+	m.add(e)
 	return e
 }
 
-// Delete removes the given entity from the manager.
+// add inserts an entity into the slot given by its id, which must be free.
+func (m *Manager) add(e *Entity) {
+	e.elem = m.entities.PushBack(e)
+	m.added++
+	e.order = m.added
+	m.slots[e.id.Index()].entity = e
+	for _, v := range m.views {
+		v.update(m, e)
+	}
+	notify(&m.observers.create, e)
+}
+
+// Delete removes the given entity from the manager, freeing its id's index for reuse. Deleting
+// an entity that has already been deleted has no effect. The entity's id is freed first, so that
+// observers see it as deleted, then the delete observers are notified, followed by the removal
+// observers for each of the entity's components and groups in order of their names.
 func (m *Manager) Delete(entity *Entity) {
-	e := m.entities.Front()
-	for ; e != nil; e = e.Next() {
-		if e.Value == entity {
-			break
-		}
+	if m.Get(entity.id) != entity {
+		return
 	}
-	if e != nil {
-		m.entities.Remove(e)
+	m.entities.Remove(entity.elem)
+	entity.elem = nil
+	i := entity.id.Index()
+	m.slots[i].entity = nil
+	m.slots[i].generation++
+	m.free = append(m.free, uint32(i))
+	notify(&m.observers.delete, entity)
+	for _, name := range m.names {
+		m.stores[name].remove(entity)
 	}
-	delete(m.ids, entity.id)
-	for tag, e := range m.tags {
-		if e == entity {
-			delete(m.tags, tag)
-		}
+	for _, g := range sortedKeys(entity.groups) {
+		notify(m.observers.groupLeft[g], entity)
+		delete(m.groups[g], entity)
+	}
+	entity.groups = nil
+	for tag := range entity.tags {
+		delete(m.tags, tag)
 	}
-	for _, g := range m.groups {
-		delete(g, entity)
+	entity.tags = nil
+	for _, v := range m.views {
+		v.remove(entity)
 	}
 }
 
+// HasComponent returns true if the given entity has a component registered under the given name.
+func (m *Manager) HasComponent(entity *Entity, name string) bool {
+	s, ok := m.stores[name]
+	return ok && s.has(entity)
+}
+
 // SetTag sets the given tag to refer to the given entity.
 func (m *Manager) SetTag(entity *Entity, tag string) {
+	if m.Get(entity.id) != entity {
+		return
+	}
+	if old, ok := m.tags[tag]; ok {
+		delete(old.tags, tag)
+		m.refresh(old, tag)
+	}
 	m.tags[tag] = entity
+	if entity.tags == nil {
+		entity.tags = make(map[string]bool)
+	}
+	entity.tags[tag] = true
+	m.refresh(entity, tag)
+	for _, f := range m.observers.tagged.fs {
+		(*f)(entity, tag)
+	}
 }
 
 // SetGroup adds the given entity to the given group(s).
 func (m *Manager) SetGroup(entity *Entity, groups ...string) {
+	if m.Get(entity.id) != entity {
+		return
+	}
+	if entity.groups == nil {
+		entity.groups = make(map[string]bool)
+	}
 	for _, g := range groups {
 		group, ok := m.groups[g]
 		if !ok {
 			group = make(map[*Entity]bool)
 			m.groups[g] = group
 		}
+		if entity.groups[g] {
+			continue
+		}
 		group[entity] = true
+		entity.groups[g] = true
+		m.refresh(entity, g)
+		notify(m.observers.groupJoined[g], entity)
 	}
 }
 
 // ClearTag removes the given tag.
 func (m *Manager) ClearTag(tag string) {
-	delete(m.tags, tag)
+	if e, ok := m.tags[tag]; ok {
+		delete(e.tags, tag)
+		delete(m.tags, tag)
+		m.refresh(e, tag)
+	}
 }
 
 // ClearGroup removes the given entity from the given group(s).
 func (m *Manager) ClearGroup(entity *Entity, groups ...string) {
 	for _, g := range groups {
-		group, ok := m.groups[g]
-		if ok {
-			delete(group, entity)
+		if entity.groups[g] {
+			notify(m.observers.groupLeft[g], entity)
+			delete(m.groups[g], entity)
+			delete(entity.groups, g)
+			m.refresh(entity, g)
+		}
+	}
+}
+
+// refresh updates the membership of the given entity in all views affected by a change to the
+// named component, group or tag.
+func (m *Manager) refresh(entity *Entity, name string) {
+	for _, v := range m.views {
+		if v.query.dependsOn(name) {
+			v.update(m, entity)
 		}
 	}
 }
@@ -121,13 +121,13 @@ func TestGroup(t *testing.T) {
 	}
 	for group, ids := range groups {
 		for _, id := range ids {
-			m.SetGroup(m.Get(id), group)
+			m.SetGroup(entities[id], group)
 		}
 	}
 	for group, ents := range groups {
 		g := make([]int, len(m.Group(group)))
 		for i, v := range m.Group(group) {
-			g[i] = v.Id()
+			g[i] = v.Id().Index()
 		}
 		sort.Ints(g)
 		if !sliceEqual(g, ents) {
@@ -148,7 +148,7 @@ func TestGroup(t *testing.T) {
 	for group, ents := range groups {
 		g := make([]int, len(m.Group(group)))
 		for i, v := range m.Group(group) {
-			g[i] = v.Id()
+			g[i] = v.Id().Index()
 		}
 		sort.Ints(g)
 		ents = append(ents, 99, 98)
@@ -168,7 +168,7 @@ func TestGroup(t *testing.T) {
 		m.ClearGroup(entities[ents[len(ents)-1]], group)
 		g := make([]int, len(m.Group(group)))
 		for i, v := range m.Group(group) {
-			g[i] = v.Id()
+			g[i] = v.Id().Index()
 		}
 		sort.Ints(g)
 		ents = ents[:len(ents)-2]
@@ -187,7 +187,7 @@ func TestGroup(t *testing.T) {
 	for group, ents := range groups {
 		g := make([]int, len(m.Group(group)))
 		for i, v := range m.Group(group) {
-			g[i] = v.Id()
+			g[i] = v.Id().Index()
 		}
 		sort.Ints(g)
 		if !sliceEqual(g, ents) {
@@ -204,7 +204,7 @@ func TestGroup(t *testing.T) {
 			m.ClearGroup(entities[ents[0]], group)
 			g := make([]int, len(m.Group(group)))
 			for i, v := range m.Group(group) {
-				g[i] = v.Id()
+				g[i] = v.Id().Index()
 			}
 			sort.Ints(g)
 			ents = ents[1:]
@@ -249,7 +249,7 @@ func TestDelete(t *testing.T) {
 	}
 	for group, ids := range groups {
 		for _, id := range ids {
-			m.SetGroup(m.Get(id), group)
+			m.SetGroup(entities[id], group)
 		}
 	}
 	for _, id := range del {
@@ -285,7 +285,7 @@ func TestDelete(t *testing.T) {
 		}
 	}
 	for _, id := range del {
-		if m.Get(id) != nil {
+		if m.Get(entities[id].Id()) != nil || m.IsAlive(entities[id].Id()) {
 			t.Errorf("Entity %v is still gettable", id)
 		}
 	}
@@ -303,9 +303,71 @@ func TestDelete(t *testing.T) {
 	for group := range groups {
 		g := m.Group(group)
 		for _, e := range g {
-			if inSlice(e.Id(), del) {
+			if inSlice(e.Id().Index(), del) {
 				t.Errorf("Entity %v is still in group %v", e.Id(), group)
 			}
 		}
 	}
 }
+
+func TestReuse(t *testing.T) {
+	m := NewManager()
+	entities := make([]*Entity, 10)
+	for i := range entities {
+		entities[i] = m.New()
+	}
+	old := entities[3].Id()
+	m.Delete(entities[3])
+	m.Delete(entities[3])
+	if m.IsAlive(old) {
+		t.Errorf("Deleted entity %v is alive", old)
+	}
+	e := m.New()
+	if e.Id().Index() != old.Index() || e.Id().Generation() != old.Generation()+1 {
+		t.Errorf("New entity %v did not reuse index of %v", e.Id(), old)
+	}
+	if m.Get(old) != nil || m.Get(e.Id()) != e || !m.IsAlive(e.Id()) {
+		t.Errorf("Stale id %v refers to new entity %v", old, e.Id())
+	}
+	if f := m.New(); f.Id().Index() != 10 {
+		t.Errorf("New entity has index %v, expected 10", f.Id().Index())
+	}
+	m.SetTag(entities[3], "stale")
+	m.SetGroup(entities[3], "stale")
+	if m.Tag("stale") != nil || len(m.Group("stale")) != 0 {
+		t.Errorf("Deleted entity was tagged or grouped")
+	}
+}
+
+func TestDefer(t *testing.T) {
+	m := NewManager()
+	a := m.New()
+	b := m.New()
+	var created, nested *Entity
+	m.DeferNew(func(e *Entity) {
+		created = e
+		m.DeferSetGroup(e, "spawned")
+		m.DeferNew(func(e *Entity) { nested = e })
+	})
+	m.DeferSetGroup(a, "doomed")
+	m.DeferDelete(a)
+	m.DeferSetGroup(a, "stale")
+	m.DeferDelete(b)
+	if len(m.All()) != 2 || m.InGroup(a, "doomed") || m.Pending() != 5 {
+		t.Errorf("Deferred changes applied before flush")
+	}
+	m.Flush()
+	if m.Pending() != 0 {
+		t.Errorf("%v changes still pending after flush", m.Pending())
+	}
+	if created == nil || nested == nil || !m.InGroup(created, "spawned") {
+		t.Fatalf("Deferred entities not created")
+	}
+	all := m.All()
+	if len(all) != 2 || all[0] != created || all[1] != nested {
+		t.Errorf("Entities after flush do not match")
+	}
+	if m.IsAlive(a.Id()) || m.IsAlive(b.Id()) || len(m.Group("doomed")) != 0 || len(m.Group("stale")) != 0 {
+		t.Errorf("Deferred deletion not applied in order")
+	}
+}
@@ -0,0 +1,150 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A Prefab is a template from which entities are spawned. Components are given in the same JSON
+// form used when saving, and are decoded by the codec of the store they are registered with.
+// A prefab inherits the components, tags and groups of its parent, with its own components
+// merged over those of the parent field by field.
+type Prefab struct {
+	Parent     *Prefab
+	Components map[string]json.RawMessage
+	Tags       []string
+	Groups     []string
+}
+
+// Spawn creates a new entity from the prefab. Each value in overrides is encoded as JSON and
+// merged over the named component of the prefab before decoding, so an override need only
+// contain the fields that differ, such as map[string]interface{}{"pos": &Position{X: 5, Y: 3}}
+// or map[string]interface{}{"sprite": map[string]string{"Animation": "idle"}}. If any component
+// cannot be decoded, an error is returned and no entity is created.
+func (m *Manager) Spawn(prefab *Prefab, overrides map[string]interface{}) (*Entity, error) {
+	components, tags, groups, err := prefab.resolve(0)
+	if err != nil {
+		return nil, err
+	}
+	for name, o := range overrides {
+		data, err := json.Marshal(o)
+		if err != nil {
+			return nil, fmt.Errorf("entity: encoding override of %q: %v", name, err)
+		}
+		components[name], err = mergeJSON(components[name], data)
+		if err != nil {
+			return nil, fmt.Errorf("entity: merging override of %q: %v", name, err)
+		}
+	}
+	// Components are set in order of their names, so that observers are notified in the same
+	// order every time.
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	setters := make([]func(e *Entity), 0, len(components))
+	for _, name := range names {
+		data := components[name]
+		s, ok := m.stores[name]
+		if !ok {
+			return nil, fmt.Errorf("entity: unknown component %q", name)
+		}
+		set, err := s.unmarshal(data)
+		if err != nil {
+			return nil, err
+		}
+		setters = append(setters, set)
+	}
+	e := m.New()
+	for _, set := range setters {
+		set(e)
+	}
+	m.SetGroup(e, groups...)
+	for _, tag := range tags {
+		m.SetTag(e, tag)
+	}
+	return e, nil
+}
+
+// maxPrefabDepth limits the length of a chain of parents, guarding against cycles.
+const maxPrefabDepth = 64
+
+// resolve flattens the prefab and its ancestors into a single set of components, tags and
+// groups.
+func (p *Prefab) resolve(depth int) (components map[string]json.RawMessage, tags, groups []string, err error) {
+	if depth > maxPrefabDepth {
+		return nil, nil, nil, fmt.Errorf("entity: prefab inheritance deeper than %v", maxPrefabDepth)
+	}
+	if p.Parent != nil {
+		components, tags, groups, err = p.Parent.resolve(depth + 1)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	} else {
+		components = make(map[string]json.RawMessage, len(p.Components))
+	}
+	for name, data := range p.Components {
+		components[name], err = mergeJSON(components[name], data)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("entity: merging prefab component %q: %v", name, err)
+		}
+	}
+	tags = append(tags, p.Tags...)
+	groups = append(groups, p.Groups...)
+	return components, tags, groups, nil
+}
+
+// mergeJSON returns over merged on top of base. Objects are merged key by key, recursively,
+// while any other value in over replaces the corresponding value in base. Keys are matched
+// without regard to case, as they are when decoding into a struct.
+func mergeJSON(base, over json.RawMessage) (json.RawMessage, error) {
+	if len(base) == 0 {
+		return over, nil
+	}
+	b, err := decodeJSON(base)
+	if err != nil {
+		return nil, err
+	}
+	o, err := decodeJSON(over)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeValues(b, o))
+}
+
+func mergeValues(base, over interface{}) interface{} {
+	b, ok := base.(map[string]interface{})
+	o, ook := over.(map[string]interface{})
+	if !ok || !ook {
+		return over
+	}
+	merged := make(map[string]interface{}, len(b)+len(o))
+	for k, v := range b {
+		merged[k] = v
+	}
+	for k, v := range o {
+		var old interface{}
+		for mk, mv := range merged {
+			if strings.EqualFold(mk, k) {
+				old = mv
+				delete(merged, mk)
+				break
+			}
+		}
+		merged[k] = mergeValues(old, v)
+	}
+	return merged
+}
+
+// decodeJSON decodes data generically, keeping numbers in their original form so that large
+// integers survive merging.
+func decodeJSON(data json.RawMessage) (v interface{}, err error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	err = d.Decode(&v)
+	return v, err
+}
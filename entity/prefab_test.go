@@ -0,0 +1,106 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+type health struct {
+	Current, Max int
+}
+
+func TestSpawn(t *testing.T) {
+	m := NewManager()
+	positions := Positions(m)
+	healths := Register[*health](m, "health")
+	base := &Prefab{
+		Components: map[string]json.RawMessage{
+			"pos":    json.RawMessage(`{"X": 1, "Y": 2}`),
+			"health": json.RawMessage(`{"Current": 10, "Max": 10}`),
+		},
+		Groups: []string{"actors"},
+	}
+	enemy := &Prefab{
+		Parent: base,
+		Components: map[string]json.RawMessage{
+			"health": json.RawMessage(`{"Max": 20}`),
+		},
+		Groups: []string{"enemies"},
+		Tags:   []string{"boss"},
+	}
+	e, err := m.Spawn(enemy, map[string]interface{}{
+		"pos": map[string]float32{"y": 5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := positions.Get(e); p == nil || *p != (Position{1, 5}) {
+		t.Errorf("Spawned position %v, expected {1 5}", p)
+	}
+	if h, _ := healths.Get(e); h == nil || *h != (health{10, 20}) {
+		t.Errorf("Spawned health %v, expected {10 20}", h)
+	}
+	if !m.InGroup(e, "actors") || !m.InGroup(e, "enemies") || m.Tag("boss") != e {
+		t.Errorf("Spawned entity missing inherited groups or tags")
+	}
+	f, err := m.Spawn(base, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h, _ := healths.Get(f); h == nil || *h != (health{10, 10}) || m.InGroup(f, "enemies") {
+		t.Errorf("Parent prefab modified by spawning child")
+	}
+	p, _ := positions.Get(f)
+	p.X = 100
+	if g, _ := m.Spawn(base, nil); g != nil {
+		if q, _ := positions.Get(g); q.X != 1 {
+			t.Errorf("Spawned entities share components")
+		}
+	}
+
+	n := len(m.All())
+	for _, prefab := range []*Prefab{
+		{Components: map[string]json.RawMessage{"vel": json.RawMessage(`{}`)}},
+		{Parent: base, Components: map[string]json.RawMessage{"health": json.RawMessage(`[]`)}},
+	} {
+		if _, err := m.Spawn(prefab, nil); err == nil {
+			t.Errorf("Spawning invalid prefab did not fail")
+		}
+	}
+	if _, err := m.Spawn(base, map[string]interface{}{"pos": "nowhere"}); err == nil {
+		t.Errorf("Spawning with invalid override did not fail")
+	}
+	loop := &Prefab{}
+	loop.Parent = loop
+	if _, err := m.Spawn(loop, nil); err == nil {
+		t.Errorf("Spawning prefab with cyclic inheritance did not fail")
+	}
+	if len(m.All()) != n {
+		t.Errorf("Failed spawns created entities")
+	}
+}
+
+func TestSpawnOrder(t *testing.T) {
+	m := NewManager()
+	names := []string{"d", "b", "e", "a", "c"}
+	var added []string
+	p := &Prefab{Components: make(map[string]json.RawMessage)}
+	for _, name := range names {
+		name := name
+		Register[int](m, name)
+		m.OnComponentAdded(name, func(e *Entity) {
+			added = append(added, name)
+		})
+		p.Components[name] = json.RawMessage(`1`)
+	}
+	for i := 0; i < 10; i++ {
+		added = added[:0]
+		if _, err := m.Spawn(p, nil); err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(added) != "[a b c d e]" {
+			t.Fatalf("Components added in order %v, expected [a b c d e]", added)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package entity
+
+import (
+	"fmt"
+	"sort"
+)
+
+// A Query selects entities by the components they have, the components they lack, the groups
+// they belong to and the tags they hold. Queries are built up by chaining, as in
+// With("pos", "sprite").Without("hidden").InGroup("enemies").
+type Query struct {
+	with, without, groups, tags []string
+}
+
+// With returns a query for entities with all of the named components.
+func With(components ...string) Query {
+	return Query{}.With(components...)
+}
+
+// Without returns a query for entities with none of the named components.
+func Without(components ...string) Query {
+	return Query{}.Without(components...)
+}
+
+// InGroup returns a query for entities in all of the named groups.
+func InGroup(groups ...string) Query {
+	return Query{}.InGroup(groups...)
+}
+
+// Tagged returns a query for entities with all of the named tags.
+func Tagged(tags ...string) Query {
+	return Query{}.Tagged(tags...)
+}
+
+// With returns a copy of q that additionally requires all of the named components.
+func (q Query) With(components ...string) Query {
+	q.with = appendNames(q.with, components)
+	return q
+}
+
+// Without returns a copy of q that additionally excludes entities with any of the named
+// components.
+func (q Query) Without(components ...string) Query {
+	q.without = appendNames(q.without, components)
+	return q
+}
+
+// InGroup returns a copy of q that additionally requires membership of all of the named groups.
+func (q Query) InGroup(groups ...string) Query {
+	q.groups = appendNames(q.groups, groups)
+	return q
+}
+
+// Tagged returns a copy of q that additionally requires all of the named tags.
+func (q Query) Tagged(tags ...string) Query {
+	q.tags = appendNames(q.tags, tags)
+	return q
+}
+
+// appendNames returns a new sorted slice, so that queries never share storage and equivalent
+// queries have the same key.
+func appendNames(names []string, more []string) []string {
+	n := make([]string, 0, len(names)+len(more))
+	n = append(append(n, names...), more...)
+	sort.Strings(n)
+	return n
+}
+
+// key returns a string identifying the query. Names are quoted so that no name, whatever
+// characters it contains, can be mistaken for part of another.
+func (q Query) key() string {
+	return fmt.Sprintf("%q%q%q%q", q.with, q.without, q.groups, q.tags)
+}
+
+// dependsOn returns true if a change to the named component, group or tag could affect the result
+// of the query.
+func (q Query) dependsOn(name string) bool {
+	for _, names := range [][]string{q.with, q.without, q.groups, q.tags} {
+		for _, n := range names {
+			if n == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (q Query) matches(m *Manager, e *Entity) bool {
+	if m.Get(e.id) != e {
+		return false
+	}
+	for _, c := range q.with {
+		if !m.HasComponent(e, c) {
+			return false
+		}
+	}
+	for _, c := range q.without {
+		if m.HasComponent(e, c) {
+			return false
+		}
+	}
+	for _, g := range q.groups {
+		if !m.InGroup(e, g) {
+			return false
+		}
+	}
+	for _, t := range q.tags {
+		if !e.tags[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// A View is the set of entities matching a query. It is kept up to date by its manager as
+// entities are created and deleted and their components, groups and tags change.
+type View struct {
+	query    Query
+	entities []*Entity
+	index    map[*Entity]int
+}
+
+// View returns a view of the entities matching the query. Views are cached, so repeated calls
+// with an equivalent query are cheap and return the same view.
+func (m *Manager) View(q Query) *View {
+	key := q.key()
+	if v, ok := m.views[key]; ok {
+		return v
+	}
+	v := &View{
+		query: q,
+		index: make(map[*Entity]int),
+	}
+	for e := m.entities.Front(); e != nil; e = e.Next() {
+		v.update(m, e.Value.(*Entity))
+	}
+	m.views[key] = v
+	return v
+}
+
+// Entities returns the entities in the view. The slice is owned by the view and must not be
+// modified, and is invalidated by any change to the entities it was obtained from.
+func (v *View) Entities() []*Entity {
+	return v.entities
+}
+
+// Len returns the number of entities in the view.
+func (v *View) Len() int {
+	return len(v.entities)
+}
+
+// Contains returns true if the given entity is in the view.
+func (v *View) Contains(e *Entity) bool {
+	_, ok := v.index[e]
+	return ok
+}
+
+// update adds or removes e from the view as appropriate.
+func (v *View) update(m *Manager, e *Entity) {
+	if v.query.matches(m, e) {
+		v.add(e)
+	} else {
+		v.remove(e)
+	}
+}
+
+func (v *View) add(e *Entity) {
+	if _, ok := v.index[e]; !ok {
+		v.index[e] = len(v.entities)
+		v.entities = append(v.entities, e)
+	}
+}
+
+func (v *View) remove(e *Entity) {
+	i, ok := v.index[e]
+	if !ok {
+		return
+	}
+	last := len(v.entities) - 1
+	v.entities[i] = v.entities[last]
+	v.index[v.entities[i]] = i
+	v.entities[last] = nil
+	v.entities = v.entities[:last]
+	delete(v.index, e)
+}
@@ -0,0 +1,124 @@
+package entity
+
+import (
+	"sort"
+	"testing"
+)
+
+// viewIds returns the sorted ids of the entities in a view.
+func viewIds(v *View) []int {
+	ids := make([]int, 0, v.Len())
+	for _, e := range v.Entities() {
+		ids = append(ids, e.Id().Index())
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// matchingIds returns the sorted ids of the entities matching a query, found by checking every
+// entity.
+func matchingIds(m *Manager, q Query) []int {
+	ids := make([]int, 0)
+	for _, e := range m.All() {
+		if q.matches(m, e) {
+			ids = append(ids, e.Id().Index())
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func TestView(t *testing.T) {
+	m := NewManager()
+	positions := Positions(m)
+	hidden := Register[bool](m, "hidden")
+	queries := []Query{
+		With("pos"),
+		With("pos").Without("hidden"),
+		Without("hidden"),
+		InGroup("enemies").With("pos"),
+		InGroup("enemies", "flying"),
+		Tagged("boss").With("pos"),
+	}
+	views := make([]*View, len(queries))
+	for i, q := range queries {
+		views[i] = m.View(q)
+	}
+	if m.View(Without("hidden").With("pos")) != views[1] {
+		t.Errorf("Equivalent query did not return the cached view")
+	}
+	check := func(step string) {
+		for i, q := range queries {
+			if got, want := viewIds(views[i]), matchingIds(m, q); !sliceEqual(got, want) {
+				t.Errorf("%v: view %v does not match (%v vs %v)", step, i, got, want)
+			}
+		}
+	}
+	entities := make([]*Entity, 50)
+	for i := range entities {
+		entities[i] = m.New()
+		if i%2 == 0 {
+			positions.Set(entities[i], &Position{})
+		}
+		if i%5 == 0 {
+			hidden.Set(entities[i], true)
+		}
+		if i%3 == 0 {
+			m.SetGroup(entities[i], "enemies")
+		}
+		if i%4 == 0 {
+			m.SetGroup(entities[i], "flying")
+		}
+		if i%8 == 0 {
+			m.SetTag(entities[i], "boss")
+		}
+	}
+	check("create")
+	for i, e := range entities {
+		if i%7 == 0 {
+			positions.Remove(e)
+		}
+		if i%10 == 0 {
+			hidden.Remove(e)
+		}
+		if i%6 == 0 {
+			m.ClearGroup(e, "enemies")
+		}
+		if i == 20 {
+			m.SetTag(e, "boss")
+		}
+	}
+	check("change")
+	m.ClearTag("boss")
+	check("untag")
+	for i, e := range entities {
+		if i%3 == 1 {
+			m.Delete(e)
+		}
+	}
+	check("delete")
+	late := m.View(With("pos").InGroup("flying"))
+	if got, want := viewIds(late), matchingIds(m, With("pos").InGroup("flying")); !sliceEqual(got, want) {
+		t.Errorf("View created late does not match (%v vs %v)", got, want)
+	}
+	for _, e := range late.Entities() {
+		if !late.Contains(e) {
+			t.Errorf("View does not contain its own entity %v", e.Id())
+		}
+	}
+}
+
+func TestQueryKey(t *testing.T) {
+	m := NewManager()
+	e := m.New()
+	m.SetGroup(e, "a,b")
+	if m.View(InGroup("a,b")) == m.View(InGroup("a", "b")) {
+		t.Errorf("Queries for different groups share a view")
+	}
+	if m.View(InGroup("a,b")).Len() != 1 || m.View(InGroup("a", "b")).Len() != 0 {
+		t.Errorf("Views of queries with punctuation in names are wrong")
+	}
+	if m.View(With("x").Without("y")) == m.View(With("x", "y")) {
+		t.Errorf("Queries with different exclusions share a view")
+	}
+}
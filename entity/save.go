@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// worldSpec is the saved form of all of a manager's entities.
+type worldSpec struct {
+	Generations []uint32
+	Entities    []entitySpec
+}
+
+// entitySpec is the saved form of a single entity.
+type entitySpec struct {
+	Id         Id
+	Tags       []string                   `json:",omitempty"`
+	Groups     []string                   `json:",omitempty"`
+	Components map[string]json.RawMessage `json:",omitempty"`
+}
+
+// Save writes all entities to w as JSON, along with their ids, tags, groups and components.
+// Components are encoded with the codec of their store.
+func (m *Manager) Save(w io.Writer) error {
+	world := worldSpec{
+		Generations: make([]uint32, len(m.slots)),
+		Entities:    make([]entitySpec, 0, m.entities.Len()),
+	}
+	for i, s := range m.slots {
+		world.Generations[i] = s.generation
+	}
+	for _, e := range m.All() {
+		spec := entitySpec{
+			Id:         e.id,
+			Tags:       sortedKeys(e.tags),
+			Groups:     sortedKeys(e.groups),
+			Components: make(map[string]json.RawMessage),
+		}
+		for _, name := range m.names {
+			s := m.stores[name]
+			if !s.has(e) {
+				continue
+			}
+			data, err := s.marshal(e)
+			if err != nil {
+				return fmt.Errorf("entity: encoding component %q: %v", name, err)
+			}
+			spec.Components[name] = data
+		}
+		world.Entities = append(world.Entities, spec)
+	}
+	return json.NewEncoder(w).Encode(&world)
+}
+
+// Load replaces all entities with those read from r, as written by Save. Every component in
+// the saved data must have been registered with the manager, so that it can be decoded by the
+// store's codec. Entities keep the ids they were saved with, and ids that were stale when saved
+// remain stale. If the data cannot be decoded, an error is returned and the existing entities
+// are left untouched.
+func (m *Manager) Load(r io.Reader) error {
+	var world worldSpec
+	if err := json.NewDecoder(r).Decode(&world); err != nil {
+		return err
+	}
+	used := make([]bool, len(world.Generations))
+	setters := make([][]func(e *Entity), len(world.Entities))
+	for i, spec := range world.Entities {
+		index := spec.Id.Index()
+		if index >= len(used) || uint32(spec.Id.Generation()) != world.Generations[index] {
+			return fmt.Errorf("entity: saved id %v does not match its slot", spec.Id)
+		}
+		if used[index] {
+			return fmt.Errorf("entity: saved id %v used more than once", spec.Id)
+		}
+		used[index] = true
+		for name, data := range spec.Components {
+			s, ok := m.stores[name]
+			if !ok {
+				return fmt.Errorf("entity: unknown component %q", name)
+			}
+			set, err := s.unmarshal(data)
+			if err != nil {
+				return err
+			}
+			setters[i] = append(setters[i], set)
+		}
+	}
+
+	for _, e := range m.All() {
+		m.Delete(e)
+	}
+	m.slots = make([]slot, len(world.Generations))
+	m.free = m.free[:0]
+	for i := len(world.Generations) - 1; i >= 0; i-- {
+		m.slots[i].generation = world.Generations[i]
+		if !used[i] {
+			m.free = append(m.free, uint32(i))
+		}
+	}
+	for i, spec := range world.Entities {
+		e := new(Entity)
+		e.id = spec.Id
+		m.add(e)
+		for _, set := range setters[i] {
+			set(e)
+		}
+		m.SetGroup(e, spec.Groups...)
+		for _, tag := range spec.Tags {
+			m.SetTag(e, tag)
+		}
+	}
+	return nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSave(t *testing.T) {
+	m := NewManager()
+	positions := Positions(m)
+	names := Register[string](m, "name")
+	entities := make([]*Entity, 20)
+	for i := range entities {
+		entities[i] = m.New()
+		positions.Set(entities[i], &Position{float32(i), float32(-i)})
+		if i%4 == 0 {
+			names.Set(entities[i], strings.Repeat("x", i))
+		}
+		if i%3 == 0 {
+			m.SetGroup(entities[i], "three", "all")
+		}
+	}
+	m.SetTag(entities[5], "five")
+	stale := entities[7].Id()
+	m.Delete(entities[7])
+	m.Delete(entities[9])
+	entities[7] = m.New()
+
+	var buf bytes.Buffer
+	if err := m.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	l := NewManager()
+	lpositions := Positions(l)
+	lnames := Register[string](l, "name")
+	l.New()
+	if err := l.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	all, lall := m.All(), l.All()
+	if len(all) != len(lall) {
+		t.Fatalf("Loaded %v entities, expected %v", len(lall), len(all))
+	}
+	for i, e := range all {
+		le := lall[i]
+		if le.Id() != e.Id() || l.Get(e.Id()) != le {
+			t.Errorf("Entity %v loaded with id %v", e.Id(), le.Id())
+		}
+		p, ok := positions.Get(e)
+		lp, lok := lpositions.Get(le)
+		if ok != lok || (ok && *lp != *p) {
+			t.Errorf("Entity %v position does not match (%v vs %v)", e.Id(), lp, p)
+		}
+		n, ok := names.Get(e)
+		ln, lok := lnames.Get(le)
+		if ok != lok || n != ln {
+			t.Errorf("Entity %v name does not match (%v vs %v)", e.Id(), ln, n)
+		}
+		for _, g := range []string{"three", "all"} {
+			if l.InGroup(le, g) != m.InGroup(e, g) {
+				t.Errorf("Entity %v group %v does not match", e.Id(), g)
+			}
+		}
+	}
+	if l.Tag("five") == nil || l.Tag("five").Id() != entities[5].Id() {
+		t.Errorf("Tag not loaded")
+	}
+	if l.IsAlive(stale) {
+		t.Errorf("Stale id %v is alive after loading", stale)
+	}
+	if e, le := m.New(), l.New(); e.Id() != le.Id() {
+		t.Errorf("New entity after loading has id %v, expected %v", le.Id(), e.Id())
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	m := NewManager()
+	Positions(m)
+	e := m.New()
+	for _, data := range []string{
+		`{"Generations":[0],"Entities":[{"Id":0,"Components":{"vel":{}}}]}`,
+		`{"Generations":[0],"Entities":[{"Id":0,"Components":{"pos":"here"}}]}`,
+		`{"Generations":[0],"Entities":[{"Id":4294967296}]}`,
+		`{"Generations":[0],"Entities":[{"Id":0},{"Id":0}]}`,
+		`{"Generations":[0],"Entities":[{"Id":1}]}`,
+	} {
+		if err := m.Load(strings.NewReader(data)); err == nil {
+			t.Errorf("Loading %v did not fail", data)
+		}
+	}
+	if m.Get(e.Id()) != e || len(m.All()) != 1 {
+		t.Errorf("Failed load modified entities")
+	}
+}
@@ -0,0 +1,49 @@
+package entity
+
+import "math"
+
+// Transform is a component that rotates, scales and flips an entity about its origin. The origin
+// is an offset from the entity's position, and is the one point that stays where it is when the
+// transform is applied.
+type Transform struct {
+	// Rotation is the angle in radians to turn clockwise on screen.
+	Rotation float32
+	// ScaleX and ScaleY stretch the entity along each of its axes. A scale of zero is treated as
+	// one, so that transforms only need to give the parts they change.
+	ScaleX, ScaleY float32
+	// FlipX mirrors the entity from left to right, and FlipY from top to bottom.
+	FlipX, FlipY     bool
+	OriginX, OriginY float32
+}
+
+// Transforms returns the manager's store of transform components, registered as "transform".
+func Transforms(m *Manager) *ComponentStore[*Transform] {
+	return Register[*Transform](m, "transform")
+}
+
+// Apply transforms a point given as an offset from the entity's position, returning the offset
+// at which it ends up. A nil transform leaves the point unchanged.
+func (t *Transform) Apply(x, y float32) (float32, float32) {
+	if t == nil {
+		return x, y
+	}
+	sx, sy := t.ScaleX, t.ScaleY
+	if sx == 0 {
+		sx = 1
+	}
+	if sy == 0 {
+		sy = 1
+	}
+	if t.FlipX {
+		sx = -sx
+	}
+	if t.FlipY {
+		sy = -sy
+	}
+	x = (x - t.OriginX) * sx
+	y = (y - t.OriginY) * sy
+	sin, cos := math.Sincos(float64(t.Rotation))
+	rx := x*float32(cos) - y*float32(sin)
+	ry := x*float32(sin) + y*float32(cos)
+	return rx + t.OriginX, ry + t.OriginY
+}
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	var none *Transform
+	if x, y := none.Apply(3, 4); x != 3 || y != 4 {
+		t.Errorf("Nil transform moved point to %v,%v, expected 3,4", x, y)
+	}
+	tests := []struct {
+		transform Transform
+		x, y      float32
+	}{
+		{Transform{}, 3, 4},
+		{Transform{ScaleX: 2, ScaleY: 3}, 6, 12},
+		{Transform{FlipX: true, OriginX: 2}, 1, 4},
+		{Transform{FlipY: true, ScaleY: 2, OriginY: 1}, 3, -5},
+		{Transform{Rotation: math.Pi / 2}, -4, 3},
+		{Transform{Rotation: math.Pi, OriginX: 1, OriginY: 1}, -1, -2},
+	}
+	for _, test := range tests {
+		x, y := test.transform.Apply(3, 4)
+		if math.Abs(float64(x-test.x)) > 1e-5 || math.Abs(float64(y-test.y)) > 1e-5 {
+			t.Errorf("%+v moved point to %v,%v, expected %v,%v", test.transform, x, y, test.x, test.y)
+		}
+	}
+}
@@ -1,105 +1,137 @@
 package huge
 
 import (
+	"io"
 	"log"
+	"math/rand"
 	"time"
 
-	"github.com/FinnStokes/huge/camera"
-	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/input"
+	"github.com/FinnStokes/huge/render"
 	"github.com/FinnStokes/huge/resource"
+	"github.com/FinnStokes/huge/scene"
 	"github.com/FinnStokes/huge/system"
-
-	"github.com/go-gl/gl"
-	"github.com/go-gl/glfw"
 )
 
 type Game struct {
-	Camera    *camera.Camera
-	Entities  *entity.Manager
+	Input     *input.Manager
 	Resources *resource.Manager
-	Systems   *system.Manager
-	ticker    [system.NumSpeeds]*time.Ticker
-	oldTime   [system.NumSpeeds]time.Time
-	running   bool
-	quitting  bool
+	Scenes    *scene.Stack
+	Window    render.Window
+	Clock     *system.Clock
+	// Rand is the game's source of random numbers, which is reproduced by replays.
+	Rand     *rand.Rand
+	seed     int64
+	recorder *input.Recorder
+	replay   *input.Replay
+	running  bool
+	quitting bool
 }
 
-func NewGame() *Game {
+// NewGame returns a game that draws into the given window, such as an opengl.Window or a
+// render.Headless for running without a display. If the window is also an input.Source, it
+// provides the game's input. The game starts with a single empty scene.
+func NewGame(window render.Window) *Game {
 	g := new(Game)
-	g.Camera = new(camera.Camera)
-	g.Entities = entity.NewManager()
+	g.Window = window
+	source, _ := window.(input.Source)
+	g.Input = input.NewManager(source)
 	g.Resources = resource.NewManager()
-	g.Systems = system.NewManager()
-	g.ticker[system.Slow] = time.NewTicker(time.Second)
-	g.ticker[system.Normal] = time.NewTicker(20 * time.Millisecond)
-	g.ticker[system.Fast] = time.NewTicker(10 * time.Millisecond)
+	g.Scenes = scene.NewStack()
+	g.Clock = system.NewClock()
+	g.Clock.BeforeTick = g.Input.Update
+	g.Seed(time.Now().UnixNano())
+	g.Scenes.Push(g.NewScene(), nil)
 	return g
 }
 
-func (g *Game) Run() {
-	defer g.terminate()
+// NewScene returns an empty scene with the components known to the game's resource manager
+// registered.
+func (g *Game) NewScene() *scene.Scene {
+	s := scene.New()
+	g.Resources.RegisterComponents(s.Entities)
+	return s
+}
 
-	var err error
-	if err = glfw.Init(); err != nil {
-		log.Fatalf("%v\n", err)
-		return
-	}
+// Scene returns the scene on top of the scene stack, which is the one being updated.
+func (g *Game) Scene() *scene.Scene {
+	return g.Scenes.Top()
+}
+
+// Push places a scene on top of the scene stack, using the transition t if it is not nil.
+func (g *Game) Push(s *scene.Scene, t scene.Transition) {
+	g.Scenes.Push(s, t)
+}
 
-	defer glfw.Terminate()
+// Pop removes and returns the scene on top of the scene stack, using the transition t if it is
+// not nil. The game quits once the last scene is popped and its transition has finished.
+func (g *Game) Pop(t scene.Transition) *scene.Scene {
+	return g.Scenes.Pop(t)
+}
 
-	if err = glfw.OpenWindow(640, 480, 16, 16, 16, 16, 0, 0, glfw.Windowed); err != nil {
+// Replace swaps the scene on top of the scene stack for s, using the transition t if it is not
+// nil, and returns the replaced scene.
+func (g *Game) Replace(s *scene.Scene, t scene.Transition) *scene.Scene {
+	return g.Scenes.Replace(s, t)
+}
+
+func (g *Game) Run() {
+	defer g.terminate()
+
+	if err := g.Window.Open(640, 480, "Draw"); err != nil {
 		log.Fatalf("%v\n", err)
 		return
 	}
 
-	defer glfw.CloseWindow()
-
-	g.Camera.Screen.Width, g.Camera.Screen.Height = 640, 480
-	g.Camera.World.X, g.Camera.World.Y = 0, 0
-	g.Camera.World.Width, g.Camera.World.Height = 640, 480
+	defer g.Window.Close()
 
-	glfw.SetWindowTitle("Draw")
-	glfw.SetSwapInterval(1)
-	glfw.SetWindowSizeCallback(func(w, h int) { g.onResize(w, h) })
+	g.Scenes.Resize(g.Window.Size())
+	g.Window.SetResizeCallback(func(w, h int) { g.onResize(w, h) })
 
 	g.running = true
-	for !g.quitting && glfw.WindowParam(glfw.Opened) == 1 {
-		select {
-		case t := <-g.ticker[system.Slow].C:
-			if !g.oldTime[system.Slow].IsZero() {
-				g.Systems.Update(system.Slow, t.Sub(g.oldTime[system.Slow]), g.Entities)
-			}
-			g.oldTime[system.Slow] = t
-		case t := <-g.ticker[system.Normal].C:
-			if !g.oldTime[system.Normal].IsZero() {
-				g.Systems.Update(system.Normal, t.Sub(g.oldTime[system.Normal]), g.Entities)
+	last := time.Now()
+	for !g.quitting && g.Window.IsOpen() && (g.Scenes.Len() > 0 || g.Scenes.Transitioning()) {
+		now := time.Now()
+		n := g.Clock.Advance(now.Sub(last), g.update)
+		last = now
+		if n == 0 {
+			// Nothing has changed since the last frame, so wait for the next tick rather than
+			// drawing it again.
+			if wait := g.Clock.Remaining(); wait > 0 {
+				time.Sleep(wait)
+				continue
 			}
-			g.oldTime[system.Normal] = t
-			gl.ClearColor(1, 1, 1, 1)
-			gl.Clear(gl.COLOR_BUFFER_BIT)
-			g.Systems.Draw(g.Camera, g.Entities)
-			glfw.SwapBuffers()
-		case t := <-g.ticker[system.Fast].C:
-			if !g.oldTime[system.Fast].IsZero() {
-				g.Systems.Update(system.Fast, t.Sub(g.oldTime[system.Fast]), g.Entities)
-			}
-			g.oldTime[system.Fast] = t
 		}
+		g.Scenes.Advance(time.Duration(n) * g.Clock.Tick)
+		r := g.Window.Renderer()
+		r.Clear(1, 1, 1, 1)
+		g.Scenes.Draw(r)
+		g.Window.SwapBuffers()
 	}
 }
 
+// Step advances the game by n clock ticks without waiting for real time to pass.
+func (g *Game) Step(n int) {
+	g.Clock.Step(n, g.update)
+	g.Scenes.Advance(time.Duration(n) * g.Clock.Tick)
+}
+
+// update updates the scenes at the given speed, reporting to their systems the input since
+// that speed was last updated.
+func (g *Game) update(speed system.Speed, dt time.Duration) {
+	g.Input.Begin(speed)
+	g.Scenes.Update(speed, dt)
+	g.Input.End()
+}
+
 func (g *Game) onResize(w, h int) {
-	gl.MatrixMode(gl.PROJECTION)
-	gl.LoadIdentity()
-	gl.Viewport(0, 0, w, h)
-	gl.Ortho(0, float64(w), float64(h), 0, -1.0, 1.0)
-	gl.MatrixMode(gl.MODELVIEW)
-	gl.LoadIdentity()
-	g.Camera.Screen.Width, g.Camera.Screen.Height = w, h
-	g.Camera.World.Width, g.Camera.World.Height = float32(w), float32(h)
+	g.Scenes.Resize(w, h)
 }
 
 func (g *Game) terminate() {
+	if err := g.StopRecording(); err != nil {
+		log.Println("recording input failed", err)
+	}
 	g.running = false
 	g.quitting = false
 }
@@ -109,6 +141,79 @@ func (g *Game) Quit() {
 }
 
 func (g *Game) SetSpeed(speed system.Speed, duration time.Duration) {
-	g.ticker[speed].Stop()
-	g.ticker[speed] = time.NewTicker(duration)
+	g.Clock.SetSpeed(speed, duration)
+}
+
+// Seed resets the game's random number generator with the given seed. The generator is reseeded
+// in place, so systems that kept a reference to Rand follow the new sequence.
+func (g *Game) Seed(seed int64) {
+	g.seed = seed
+	if g.Rand == nil {
+		g.Rand = rand.New(rand.NewSource(seed))
+		return
+	}
+	g.Rand.Seed(seed)
+}
+
+// Record starts recording the input for every tick to w, along with the random seed and clock
+// tick, so that the session can be replayed. For the replay to be exact, recording must start
+// before the first tick is run. Recording stops when Run returns or StopRecording is called.
+func (g *Game) Record(w io.Writer) error {
+	if err := g.StopRecording(); err != nil {
+		return err
+	}
+	r, err := input.NewRecorder(w, g.Input.Source(), input.Header{Seed: g.seed, Tick: g.Clock.Tick})
+	if err != nil {
+		return err
+	}
+	g.recorder = r
+	g.Input.SetSource(r)
+	return nil
+}
+
+// StopRecording finishes writing the recording started by Record, returning the first error
+// that occurred while recording.
+func (g *Game) StopRecording() error {
+	if g.recorder == nil {
+		return nil
+	}
+	r := g.recorder
+	g.recorder = nil
+	if g.Input.Source() == r {
+		g.Input.SetSource(r.Source())
+	}
+	return r.Flush()
+}
+
+// Replay replaces the game's input with a recording read from r, and restores the random seed
+// and clock tick it was recorded with. It must be called before anything that uses Rand is set
+// up and before the first tick is run.
+func (g *Game) Replay(r io.Reader) error {
+	p, err := input.NewReplay(r)
+	if err != nil {
+		return err
+	}
+	g.Seed(p.Seed)
+	g.Clock.Tick = p.Tick
+	g.replay = p
+	g.Input.SetSource(p)
+	return nil
+}
+
+// Replaying returns true while a replay started by Replay has ticks left to play.
+func (g *Game) Replaying() bool {
+	return g.replay != nil && !g.replay.Done()
+}
+
+// FastForward runs the remainder of a replay immediately, without drawing or waiting for real
+// time to pass, so that recorded sessions can be used as regression tests. It returns an error
+// if the recording was corrupt.
+func (g *Game) FastForward() error {
+	if g.replay == nil {
+		return nil
+	}
+	for !g.replay.Done() {
+		g.Step(1)
+	}
+	return g.replay.Err()
 }
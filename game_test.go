@@ -1,28 +1,231 @@
 package huge
 
 import (
+	"bytes"
+	"image/color"
+	"math/rand"
 	"testing"
+	"time"
 
+	"github.com/FinnStokes/huge/camera"
 	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/input"
+	"github.com/FinnStokes/huge/render"
+	"github.com/FinnStokes/huge/scene"
 	"github.com/FinnStokes/huge/sprite"
 	"github.com/FinnStokes/huge/system"
 )
 
+// quitter is a system that quits the game after drawing a fixed number of frames.
+type quitter struct {
+	g      *Game
+	frames int
+}
+
+func (q *quitter) Update(dt time.Duration, entities *entity.Manager) {}
+
+func (q *quitter) Draw(r render.Renderer, c *camera.Camera, entities *entity.Manager) {
+	q.frames--
+	if q.frames <= 0 {
+		q.g.Quit()
+	}
+}
+
 func TestInit(t *testing.T) {
-	g := NewGame()
+	g := NewGame(render.NewHeadless(640, 480))
 	g.Quit()
 	g.Run()
 }
 
 func TestRender(t *testing.T) {
-	g := NewGame()
-	g.Systems.AddSystem(system.Normal, sprite.NewManager())
-	e := g.Entities.New()
-	e.Components["pos"] = &entity.Position{100, 100}
-	var err error
-	e.Components["sprite"], err = g.Resources.GetSprite("sprite")
+	w := render.NewHeadless(640, 480)
+	g := NewGame(w)
+	g.Scene().Systems.AddSystem(system.Normal, sprite.NewManager())
+	g.Scene().Systems.AddSystem(system.Normal, &quitter{g, 1})
+	e := g.Scene().Entities.New()
+	entity.Positions(g.Scene().Entities).Set(e, &entity.Position{X: 100, Y: 100})
+	s, err := g.Resources.GetSprite("sprite")
 	if err != nil {
 		t.Fatal(err)
 	}
+	sprite.Sprites(g.Scene().Entities).Set(e, s)
+	g.Run()
+	img := w.Image()
+	if c := img.RGBAAt(132, 116); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("Sprite pixel is %v, expected red", c)
+	}
+	if c := img.RGBAAt(105, 105); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("Transparent sprite pixel is %v, expected white", c)
+	}
+}
+
+// popper is a system that pops its scene with a transition on the first frame it draws.
+type popper struct {
+	g      *Game
+	frames int
+}
+
+func (p *popper) Update(dt time.Duration, entities *entity.Manager) {}
+
+func (p *popper) Draw(r render.Renderer, c *camera.Camera, entities *entity.Manager) {
+	p.frames++
+	if p.frames == 1 {
+		p.g.Pop(scene.Fade(50*time.Millisecond, 0, 0, 0))
+	}
+}
+
+func TestPopLast(t *testing.T) {
+	g := NewGame(render.NewHeadless(640, 480))
+	p := &popper{g: g}
+	g.Scene().Systems.AddSystem(system.Normal, p)
 	g.Run()
+	if p.frames < 2 || g.Scenes.Transitioning() {
+		t.Errorf("Drew %v frames, expected the transition from the last scene to finish", p.frames)
+	}
+}
+
+func TestStep(t *testing.T) {
+	g := NewGame(render.NewHeadless(640, 480))
+	g.Scene().Systems.AddSystem(system.Normal, sprite.NewManager())
+	s, err := g.Resources.GetSprite("sprite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sprite.Sprites(g.Scene().Entities).Set(g.Scene().Entities.New(), s)
+	g.Step(24)
+	if s.CurrentFrame != 0 {
+		t.Errorf("Sprite on frame %v after 240ms, expected 0", s.CurrentFrame)
+	}
+	g.Step(2)
+	if s.CurrentFrame != 1 {
+		t.Errorf("Sprite on frame %v after 260ms, expected 1", s.CurrentFrame)
+	}
+}
+
+func TestSave(t *testing.T) {
+	g := NewGame(render.NewHeadless(640, 480))
+	s, err := g.Resources.GetSprite("sprite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.CurrentAnimation = s.Animations["idle"]
+	s.CurrentFrame = 5
+	s.Entered = true
+	sprite.Sprites(g.Scene().Entities).Set(g.Scene().Entities.New(), s)
+	var buf bytes.Buffer
+	if err := g.Scene().Entities.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	g = NewGame(render.NewHeadless(640, 480))
+	if err := g.Scene().Entities.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	sprites := sprite.Sprites(g.Scene().Entities).Components()
+	if len(sprites) != 1 {
+		t.Fatalf("Loaded %v sprites, expected 1", len(sprites))
+	}
+	if l := sprites[0]; l.Name != "sprite" || l.AnimationName() != "idle" || l.CurrentFrame != 5 || !l.Entered {
+		t.Errorf("Loaded sprite %v on %v frame %v, entered %v, expected sprite on idle frame 5 already entered", l.Name, l.AnimationName(), l.CurrentFrame, l.Entered)
+	}
+}
+
+// jumper is a system that counts how many times the jump action is pressed.
+type jumper struct {
+	in    *input.Manager
+	jumps int
+}
+
+func (j *jumper) Update(dt time.Duration, entities *entity.Manager) {
+	if j.in.ActionPressed("jump") {
+		j.jumps++
+	}
+}
+
+func (j *jumper) Draw(r render.Renderer, c *camera.Camera, entities *entity.Manager) {}
+
+func TestInput(t *testing.T) {
+	g := NewGame(render.NewHeadless(640, 480))
+	script := new(input.Script)
+	script.Hold(input.KeySpace, 2, 5)
+	script.Hold(input.KeySpace, 8, 9)
+	g.Input.SetSource(script)
+	g.Input.Bind("jump", input.Binding{Device: input.Keyboard, Key: input.KeySpace})
+	j := &jumper{in: g.Input}
+	g.Scene().Systems.AddSystem(system.Fast, j)
+	g.Step(20)
+	if j.jumps != 2 {
+		t.Errorf("Jumped %v times, expected 2", j.jumps)
+	}
+}
+
+// wanderer is a system that moves every entity a random amount while the "move" action is held.
+type wanderer struct {
+	g *Game
+}
+
+func (w *wanderer) Update(dt time.Duration, entities *entity.Manager) {
+	if !w.g.Input.ActionHeld("move") {
+		return
+	}
+	for _, p := range entity.Positions(entities).Components() {
+		p.X += w.g.Rand.Float32()
+		p.Y += w.g.Rand.Float32()
+	}
+}
+
+func (w *wanderer) Draw(r render.Renderer, c *camera.Camera, entities *entity.Manager) {}
+
+// wander runs a game with a wanderer for the given setup and returns where the entity ended up.
+func wander(t *testing.T, setup func(g *Game)) entity.Position {
+	g := NewGame(render.NewHeadless(640, 480))
+	setup(g)
+	g.Input.Bind("move", input.Binding{Device: input.Keyboard, Key: input.KeyRight})
+	g.Scene().Systems.AddSystem(system.Normal, &wanderer{g})
+	p := &entity.Position{}
+	entity.Positions(g.Scene().Entities).Set(g.Scene().Entities.New(), p)
+	if g.Replaying() {
+		if err := g.FastForward(); err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		g.Step(50)
+	}
+	if err := g.StopRecording(); err != nil {
+		t.Fatal(err)
+	}
+	return *p
+}
+
+func TestReplay(t *testing.T) {
+	var buf bytes.Buffer
+	recorded := wander(t, func(g *Game) {
+		script := new(input.Script)
+		script.Hold(input.KeyRight, 3, 17)
+		script.Hold(input.KeyRight, 30, 45)
+		g.Input.SetSource(script)
+		if err := g.Record(&buf); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if recorded.X == 0 || recorded.Y == 0 {
+		t.Fatalf("Entity did not move while recording")
+	}
+	replayed := wander(t, func(g *Game) {
+		if err := g.Replay(&buf); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if replayed != recorded {
+		t.Errorf("Entity replayed to %v, recorded at %v", replayed, recorded)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	g := NewGame(render.NewHeadless(640, 480))
+	kept := g.Rand
+	g.Seed(42)
+	if got, want := kept.Int63(), rand.New(rand.NewSource(42)).Int63(); got != want {
+		t.Errorf("Generator kept before seeding gave %v, expected %v", got, want)
+	}
 }
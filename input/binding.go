@@ -0,0 +1,89 @@
+package input
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Device identifies the kind of physical input a binding refers to.
+type Device int
+
+// The devices that can be bound to actions.
+const (
+	Keyboard Device = iota
+	Mouse
+	JoystickButton
+	JoystickAxis
+)
+
+// A Binding maps a single physical input to an action. Joystick axes count as held once they
+// pass the manager's dead zone in the binding's direction, which is 1 or -1.
+type Binding struct {
+	Device    Device
+	Key       Key
+	Mouse     MouseButton
+	Joystick  int
+	Button    int
+	Axis      int
+	Direction float32
+}
+
+// Bindings maps action names to the physical inputs that trigger them.
+type Bindings map[string][]Binding
+
+// UnmarshalJSON reads a binding in one of the forms {"key": "space"}, {"mouse": "left"},
+// {"joystick": 0, "button": 1} or {"joystick": 0, "axis": 1, "direction": -1}.
+func (b *Binding) UnmarshalJSON(data []byte) error {
+	var spec struct {
+		Key       *Key
+		Mouse     *MouseButton
+		Joystick  int
+		Button    *int
+		Axis      *int
+		Direction float32
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	*b = Binding{Joystick: spec.Joystick}
+	switch {
+	case spec.Key != nil:
+		b.Device = Keyboard
+		b.Key = *spec.Key
+	case spec.Mouse != nil:
+		b.Device = Mouse
+		b.Mouse = *spec.Mouse
+	case spec.Button != nil:
+		b.Device = JoystickButton
+		b.Button = *spec.Button
+	case spec.Axis != nil:
+		b.Device = JoystickAxis
+		b.Axis = *spec.Axis
+		b.Direction = spec.Direction
+		if b.Direction == 0 {
+			b.Direction = 1
+		}
+	default:
+		return errors.New("input: binding has no key, mouse button, joystick button or axis")
+	}
+	if b.Joystick < 0 || b.Joystick >= MaxJoysticks || b.Button < 0 || b.Button >= MaxButtons ||
+		b.Axis < 0 || b.Axis >= MaxAxes || int(b.Key) < 0 || int(b.Key) >= NumKeys {
+		return errors.New("input: binding out of range")
+	}
+	return nil
+}
+
+// active returns true if the binding's input is held in the given state.
+func (b *Binding) active(s *State, deadZone float32) bool {
+	switch b.Device {
+	case Keyboard:
+		return s.Keys[b.Key]
+	case Mouse:
+		return s.MouseButtons[b.Mouse]
+	case JoystickButton:
+		return s.Joysticks[b.Joystick].Buttons[b.Button]
+	case JoystickAxis:
+		return s.Joysticks[b.Joystick].Axes[b.Axis]*b.Direction >= deadZone
+	}
+	return false
+}
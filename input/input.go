@@ -0,0 +1,180 @@
+// Package input tracks the state of the keyboard, mouse and joysticks from tick to tick, and maps
+// physical inputs to named actions.
+package input
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Key identifies a key on the keyboard. Printable keys are represented by their upper case
+// character, as in Key('A') or Key('1'), while other keys have values from KeySpecial up.
+type Key int
+
+// Named keys. KeySpace and the printable keys are their characters, while the rest are special.
+const (
+	KeySpace Key = ' '
+)
+
+const (
+	KeySpecial Key = 256 + iota
+	KeyEscape
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyInsert
+	KeyDelete
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyLeftShift
+	KeyRightShift
+	KeyLeftCtrl
+	KeyRightCtrl
+	KeyLeftAlt
+	KeyRightAlt
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+	NumKeys int = iota + 256
+)
+
+var keyNames = map[Key]string{
+	KeySpace:      "space",
+	KeyEscape:     "escape",
+	KeyEnter:      "enter",
+	KeyTab:        "tab",
+	KeyBackspace:  "backspace",
+	KeyInsert:     "insert",
+	KeyDelete:     "delete",
+	KeyHome:       "home",
+	KeyEnd:        "end",
+	KeyPageUp:     "pageup",
+	KeyPageDown:   "pagedown",
+	KeyUp:         "up",
+	KeyDown:       "down",
+	KeyLeft:       "left",
+	KeyRight:      "right",
+	KeyLeftShift:  "lshift",
+	KeyRightShift: "rshift",
+	KeyLeftCtrl:   "lctrl",
+	KeyRightCtrl:  "rctrl",
+	KeyLeftAlt:    "lalt",
+	KeyRightAlt:   "ralt",
+}
+
+func init() {
+	for k := KeyF1; k <= KeyF12; k++ {
+		keyNames[k] = fmt.Sprintf("f%d", k-KeyF1+1)
+	}
+}
+
+// String returns the name of the key, as accepted by UnmarshalText.
+func (k Key) String() string {
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+	if k > ' ' && k < KeySpecial {
+		return strings.ToLower(string(rune(k)))
+	}
+	return fmt.Sprintf("Key(%d)", int(k))
+}
+
+// UnmarshalText allows keys to be given by name, such as "a", "space" or "left", in JSON files.
+func (k *Key) UnmarshalText(text []byte) error {
+	name := strings.ToLower(string(text))
+	for key, n := range keyNames {
+		if n == name {
+			*k = key
+			return nil
+		}
+	}
+	if r := []rune(strings.ToUpper(name)); len(r) == 1 && r[0] > ' ' && r[0] < rune(KeySpecial) {
+		*k = Key(r[0])
+		return nil
+	}
+	return fmt.Errorf("input: unknown key %q", text)
+}
+
+// A MouseButton identifies a button on the mouse.
+type MouseButton int
+
+// The mouse buttons, with NumMouseButtons tracking how many there are.
+const (
+	MouseLeft MouseButton = iota
+	MouseRight
+	MouseMiddle
+	NumMouseButtons int = iota
+)
+
+var mouseNames = [NumMouseButtons]string{"left", "right", "middle"}
+
+// String returns the name of the button, as accepted by UnmarshalText.
+func (b MouseButton) String() string {
+	if b < 0 || int(b) >= NumMouseButtons {
+		return fmt.Sprintf("MouseButton(%d)", int(b))
+	}
+	return mouseNames[b]
+}
+
+// UnmarshalText allows mouse buttons to be given by name, such as "left", in JSON files.
+func (b *MouseButton) UnmarshalText(text []byte) error {
+	for i, name := range mouseNames {
+		if strings.EqualFold(string(text), name) {
+			*b = MouseButton(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("input: unknown mouse button %q", text)
+}
+
+// Limits on the number of joysticks, and the axes and buttons of each, that are tracked.
+const (
+	MaxJoysticks = 4
+	MaxAxes      = 8
+	MaxButtons   = 16
+)
+
+// Joystick holds the state of a single joystick or gamepad. Axes range from -1 to 1.
+type Joystick struct {
+	Present bool
+	Axes    [MaxAxes]float32
+	Buttons [MaxButtons]bool
+}
+
+// State is a snapshot of all input devices. It contains no references, so it can be copied by
+// assignment.
+type State struct {
+	Keys         [NumKeys]bool
+	MouseButtons [NumMouseButtons]bool
+	MouseX       int
+	MouseY       int
+	Joysticks    [MaxJoysticks]Joystick
+}
+
+// A Source provides the state of input devices. Poll is called once per tick to fill in the
+// current state.
+type Source interface {
+	Poll(s *State)
+}
+
+// none is a source with no input.
+type none struct{}
+
+func (none) Poll(s *State) {
+	*s = State{}
+}
@@ -0,0 +1,227 @@
+package input
+
+import (
+	"github.com/FinnStokes/huge/camera"
+	"github.com/FinnStokes/huge/system"
+)
+
+// Manager polls a source once per tick, keeping the current and previous state so that inputs
+// can be queried as pressed (went down), held (down this tick) or released (went up).
+//
+// Presses and releases are normally those of the current tick. While systems at a speed are
+// being updated, between calls to Begin and End, they are instead all those since the speed was
+// last updated, so that systems updated less often than every tick do not miss them. An input
+// that goes down and back up between updates is then reported as both pressed and released.
+type Manager struct {
+	// DeadZone is how far a joystick axis must move before a binding to it counts as held.
+	DeadZone float32
+	source   Source
+	current  State
+	previous State
+	bindings Bindings
+	tick     edges
+	speeds   [system.NumSpeeds]edges
+	edges    *edges
+	speed    system.Speed
+}
+
+// edges records which inputs have gone down or up, or both.
+type edges struct {
+	keys    [NumKeys]edge
+	mouse   [NumMouseButtons]edge
+	actions map[string]edge
+}
+
+type edge uint8
+
+const (
+	pressed edge = 1 << iota
+	released
+)
+
+// add records the presses and releases in o as well.
+func (e *edges) add(o *edges) {
+	for k, v := range o.keys {
+		e.keys[k] |= v
+	}
+	for b, v := range o.mouse {
+		e.mouse[b] |= v
+	}
+	for a, v := range o.actions {
+		e.actions[a] |= v
+	}
+}
+
+// clear forgets all presses and releases.
+func (e *edges) clear() {
+	e.keys = [NumKeys]edge{}
+	e.mouse = [NumMouseButtons]edge{}
+	for a := range e.actions {
+		delete(e.actions, a)
+	}
+}
+
+// change returns the edge between an input that was down or up and now is down or up.
+func change(was, is bool) edge {
+	if is && !was {
+		return pressed
+	}
+	if was && !is {
+		return released
+	}
+	return 0
+}
+
+// NewManager returns an input manager that polls the given source, or no source if it is nil.
+func NewManager(source Source) *Manager {
+	m := new(Manager)
+	m.DeadZone = 0.5
+	m.SetSource(source)
+	m.bindings = make(Bindings)
+	m.tick.actions = make(map[string]edge)
+	for s := range m.speeds {
+		m.speeds[s].actions = make(map[string]edge)
+	}
+	m.edges = &m.tick
+	return m
+}
+
+// SetSource changes the source that the manager polls, such as to replace real devices with a
+// Script in tests.
+func (m *Manager) SetSource(source Source) {
+	if source == nil {
+		source = none{}
+	}
+	m.source = source
+}
+
+// Source returns the source that the manager polls.
+func (m *Manager) Source() Source {
+	return m.source
+}
+
+// Update polls the source for the state of the next tick, recording its presses and releases for
+// every speed.
+func (m *Manager) Update() {
+	m.previous = m.current
+	m.source.Poll(&m.current)
+	m.tick.clear()
+	for k := range m.current.Keys {
+		m.tick.keys[k] = change(m.previous.Keys[k], m.current.Keys[k])
+	}
+	for b := range m.current.MouseButtons {
+		m.tick.mouse[b] = change(m.previous.MouseButtons[b], m.current.MouseButtons[b])
+	}
+	for a := range m.bindings {
+		if e := change(m.action(a, &m.previous), m.action(a, &m.current)); e != 0 {
+			m.tick.actions[a] = e
+		}
+	}
+	for s := range m.speeds {
+		m.speeds[s].add(&m.tick)
+	}
+}
+
+// Begin makes queries report the presses and releases since systems at the given speed were last
+// updated, until End is called. The game calls Begin and End around each update of its scenes.
+func (m *Manager) Begin(speed system.Speed) {
+	m.speed = speed
+	m.edges = &m.speeds[speed]
+}
+
+// End forgets the presses and releases reported to the speed given to Begin, as its systems have
+// now seen them, and makes queries report those of the current tick again.
+func (m *Manager) End() {
+	if m.edges != &m.tick {
+		m.speeds[m.speed].clear()
+	}
+	m.edges = &m.tick
+}
+
+// State returns the state of all devices for the current tick.
+func (m *Manager) State() *State {
+	return &m.current
+}
+
+// Held returns true if the key is down.
+func (m *Manager) Held(k Key) bool {
+	return m.current.Keys[k]
+}
+
+// Pressed returns true if the key went down.
+func (m *Manager) Pressed(k Key) bool {
+	return m.edges.keys[k]&pressed != 0
+}
+
+// Released returns true if the key went up.
+func (m *Manager) Released(k Key) bool {
+	return m.edges.keys[k]&released != 0
+}
+
+// MouseHeld returns true if the mouse button is down.
+func (m *Manager) MouseHeld(b MouseButton) bool {
+	return m.current.MouseButtons[b]
+}
+
+// MousePressed returns true if the mouse button went down.
+func (m *Manager) MousePressed(b MouseButton) bool {
+	return m.edges.mouse[b]&pressed != 0
+}
+
+// MouseReleased returns true if the mouse button went up.
+func (m *Manager) MouseReleased(b MouseButton) bool {
+	return m.edges.mouse[b]&released != 0
+}
+
+// Mouse returns the position of the mouse in screen pixels.
+func (m *Manager) Mouse() (x, y int) {
+	return m.current.MouseX, m.current.MouseY
+}
+
+// MouseWorld returns the position of the mouse in world coordinates, as seen through the given
+// camera.
+func (m *Manager) MouseWorld(c *camera.Camera) (x, y float32) {
+	return c.ScreenToWorld(float32(m.current.MouseX), float32(m.current.MouseY))
+}
+
+// Joystick returns the state of the numbered joystick.
+func (m *Manager) Joystick(joy int) *Joystick {
+	return &m.current.Joysticks[joy]
+}
+
+// Bind adds the given bindings to the named action.
+func (m *Manager) Bind(action string, bindings ...Binding) {
+	m.bindings[action] = append(m.bindings[action], bindings...)
+}
+
+// SetBindings replaces all action bindings, such as with those loaded from a resource.
+func (m *Manager) SetBindings(b Bindings) {
+	m.bindings = make(Bindings, len(b))
+	for action, bindings := range b {
+		m.Bind(action, bindings...)
+	}
+}
+
+// ActionHeld returns true if any input bound to the action is held.
+func (m *Manager) ActionHeld(action string) bool {
+	return m.action(action, &m.current)
+}
+
+// ActionPressed returns true if the action went from not held to held.
+func (m *Manager) ActionPressed(action string) bool {
+	return m.edges.actions[action]&pressed != 0
+}
+
+// ActionReleased returns true if the action went from held to not held.
+func (m *Manager) ActionReleased(action string) bool {
+	return m.edges.actions[action]&released != 0
+}
+
+func (m *Manager) action(action string, s *State) bool {
+	for i := range m.bindings[action] {
+		if m.bindings[action][i].active(s, m.DeadZone) {
+			return true
+		}
+	}
+	return false
+}
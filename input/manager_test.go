@@ -0,0 +1,147 @@
+package input
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/FinnStokes/huge/camera"
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/render"
+	"github.com/FinnStokes/huge/system"
+)
+
+func TestKeys(t *testing.T) {
+	script := new(Script)
+	script.Hold('A', 1, 3)
+	script.HoldMouse(MouseRight, 2, 3)
+	m := NewManager(script)
+	expected := []struct{ pressed, held, released, mouse bool }{
+		{false, false, false, false},
+		{true, true, false, false},
+		{false, true, false, true},
+		{false, false, true, false},
+		{false, false, false, false},
+	}
+	for i, e := range expected {
+		m.Update()
+		if m.Pressed('A') != e.pressed || m.Held('A') != e.held || m.Released('A') != e.released {
+			t.Errorf("Tick %v: key pressed %v, held %v, released %v", i, m.Pressed('A'), m.Held('A'), m.Released('A'))
+		}
+		if m.MousePressed(MouseRight) != e.mouse || m.MouseHeld(MouseRight) != e.mouse || m.MouseReleased(MouseRight) != e.released {
+			t.Errorf("Tick %v: mouse pressed %v, held %v, released %v", i,
+				m.MousePressed(MouseRight), m.MouseHeld(MouseRight), m.MouseReleased(MouseRight))
+		}
+	}
+}
+
+func TestActions(t *testing.T) {
+	var b Bindings
+	err := json.Unmarshal([]byte(`{
+		"jump": [{"key": "space"}, {"joystick": 1, "button": 2}],
+		"left": [{"key": "a"}, {"key": "left"}, {"joystick": 0, "axis": 0, "direction": -1}],
+		"fire": [{"mouse": "left"}]
+	}`), &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := new(Script)
+	script.Hold(KeySpace, 0, 1)
+	script.At(1).Joysticks[1].Buttons[2] = true
+	script.Hold('A', 3, 4)
+	script.Hold(KeyLeft, 4, 5)
+	script.At(5).Joysticks[0].Axes[0] = -0.8
+	script.At(6).Joysticks[0].Axes[0] = -0.2
+	script.HoldMouse(MouseLeft, 6, 7)
+	m := NewManager(script)
+	m.SetBindings(b)
+	expected := []struct{ jump, left, fire bool }{
+		{true, false, false},
+		{true, false, false},
+		{false, false, false},
+		{false, true, false},
+		{false, true, false},
+		{false, true, false},
+		{false, false, true},
+	}
+	for i, e := range expected {
+		m.Update()
+		if m.ActionHeld("jump") != e.jump || m.ActionHeld("left") != e.left || m.ActionHeld("fire") != e.fire {
+			t.Errorf("Tick %v: jump %v, left %v, fire %v", i, m.ActionHeld("jump"), m.ActionHeld("left"), m.ActionHeld("fire"))
+		}
+		if i == 1 && (m.ActionPressed("jump") || m.ActionReleased("jump")) {
+			t.Errorf("Tick %v: jump pressed or released while held", i)
+		}
+		if i == 2 && !m.ActionReleased("jump") {
+			t.Errorf("Tick %v: jump not released", i)
+		}
+	}
+	for _, data := range []string{`{}`, `{"key": "nokey"}`, `{"mouse": "side"}`, `{"joystick": 9, "button": 0}`} {
+		var b Binding
+		if err := json.Unmarshal([]byte(data), &b); err == nil {
+			t.Errorf("Invalid binding %v accepted", data)
+		}
+	}
+}
+
+func TestMouseWorld(t *testing.T) {
+	script := new(Script)
+	script.MoveMouse(320, 120, 0)
+	m := NewManager(script)
+	m.Update()
+	c := &camera.Camera{
+		World:  camera.Rectangle{X: 100, Y: 50, Width: 320, Height: 240},
+		Screen: camera.Screen{Width: 640, Height: 480},
+	}
+	if x, y := m.MouseWorld(c); x != 260 || y != 110 {
+		t.Errorf("Mouse at (%v, %v) in world, expected (260, 110)", x, y)
+	}
+}
+
+// presser is a system that counts the updates in which the "jump" action was pressed and released.
+type presser struct {
+	in                *Manager
+	presses, releases int
+}
+
+func (p *presser) Update(dt time.Duration, entities *entity.Manager) {
+	if p.in.ActionPressed("jump") {
+		p.presses++
+	}
+	if p.in.ActionReleased("jump") {
+		p.releases++
+	}
+}
+
+func (p *presser) Draw(r render.Renderer, c *camera.Camera, entities *entity.Manager) {}
+
+func TestSpeeds(t *testing.T) {
+	script := new(Script)
+	script.Hold(KeySpace, 2, 3)
+	script.Hold(KeySpace, 5, 9)
+	m := NewManager(script)
+	m.Bind("jump", Binding{Device: Keyboard, Key: KeySpace})
+	systems := system.NewManager()
+	speeds := []system.Speed{system.Slow, system.Normal, system.Fast}
+	pressers := make([]*presser, len(speeds))
+	for i, speed := range speeds {
+		pressers[i] = &presser{in: m}
+		systems.AddSystem(speed, pressers[i])
+	}
+	c := system.NewClock()
+	c.BeforeTick = m.Update
+	c.Step(100, func(speed system.Speed, dt time.Duration) {
+		m.Begin(speed)
+		systems.Update(speed, dt, nil)
+		m.End()
+	})
+	// The slow system sees every press and release at once in its only update.
+	for i, expected := range []int{1, 2, 2} {
+		if p := pressers[i]; p.presses != expected || p.releases != expected {
+			t.Errorf("%v system saw %v presses and %v releases, expected %v", speeds[i], p.presses, p.releases, expected)
+		}
+	}
+	if m.Pressed(KeySpace) || m.Released(KeySpace) {
+		t.Errorf("Presses reported outside an update after the key was left alone")
+	}
+}
@@ -0,0 +1,265 @@
+package input
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// recordMagic begins every recording, followed by the format version.
+const (
+	recordMagic   = "HUGE"
+	recordVersion = 1
+)
+
+// Header holds the information needed to reproduce a recorded session alongside its input: the
+// seed of the game's random number generator and the length of a clock tick.
+type Header struct {
+	Seed int64
+	Tick time.Duration
+}
+
+// A Recorder is a source that passes on the state of another source while recording it. Each
+// tick is stored as the changes from the previous tick, so recordings stay small.
+type Recorder struct {
+	source Source
+	w      *bufio.Writer
+	prev   State
+	err    error
+}
+
+// NewRecorder writes the header to w and returns a recorder of the states polled from source.
+func NewRecorder(w io.Writer, source Source, h Header) (*Recorder, error) {
+	r := &Recorder{source: source, w: bufio.NewWriter(w)}
+	if r.source == nil {
+		r.source = none{}
+	}
+	r.w.WriteString(recordMagic)
+	r.putUvarint(recordVersion)
+	r.putVarint(h.Seed)
+	r.putVarint(int64(h.Tick))
+	return r, r.err
+}
+
+// Poll polls the underlying source and records the state it gives.
+func (r *Recorder) Poll(s *State) {
+	r.source.Poll(s)
+	if r.err == nil {
+		r.write(s)
+		r.prev = *s
+	}
+}
+
+// Source returns the source being recorded.
+func (r *Recorder) Source() Source {
+	return r.source
+}
+
+// Flush writes any buffered data to the underlying writer, returning the first error that
+// occurred while recording.
+func (r *Recorder) Flush() error {
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+	return r.err
+}
+
+func (r *Recorder) write(s *State) {
+	var changed []uint64
+	for k := range s.Keys {
+		if s.Keys[k] != r.prev.Keys[k] {
+			changed = append(changed, uint64(k))
+		}
+	}
+	r.putUvarint(uint64(len(changed)))
+	for _, k := range changed {
+		r.putUvarint(k)
+	}
+	var buttons byte
+	for b, down := range s.MouseButtons {
+		if down {
+			buttons |= 1 << uint(b)
+		}
+	}
+	r.putByte(buttons)
+	r.putVarint(int64(s.MouseX - r.prev.MouseX))
+	r.putVarint(int64(s.MouseY - r.prev.MouseY))
+	var present byte
+	for j := range s.Joysticks {
+		if s.Joysticks[j].Present {
+			present |= 1 << uint(j)
+		}
+	}
+	r.putByte(present)
+	for j := range s.Joysticks {
+		joy, prev := &s.Joysticks[j], &r.prev.Joysticks[j]
+		var pressed uint64
+		for b, down := range joy.Buttons {
+			if down {
+				pressed |= 1 << uint(b)
+			}
+		}
+		r.putUvarint(pressed)
+		var moved byte
+		for a := range joy.Axes {
+			if joy.Axes[a] != prev.Axes[a] {
+				moved |= 1 << uint(a)
+			}
+		}
+		r.putByte(moved)
+		for a := range joy.Axes {
+			if moved&(1<<uint(a)) != 0 {
+				var buf [4]byte
+				binary.LittleEndian.PutUint32(buf[:], math.Float32bits(joy.Axes[a]))
+				r.put(buf[:])
+			}
+		}
+	}
+}
+
+func (r *Recorder) put(b []byte) {
+	if r.err == nil {
+		_, r.err = r.w.Write(b)
+	}
+}
+
+func (r *Recorder) putByte(b byte) {
+	r.put([]byte{b})
+}
+
+func (r *Recorder) putUvarint(v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	r.put(buf[:binary.PutUvarint(buf[:], v)])
+}
+
+func (r *Recorder) putVarint(v int64) {
+	var buf [binary.MaxVarintLen64]byte
+	r.put(buf[:binary.PutVarint(buf[:], v)])
+}
+
+// A Replay is a source that plays back a recording made by a Recorder. Once the recording runs
+// out, or if it is found to be corrupt, nothing is held and the mouse stays where it was.
+type Replay struct {
+	Header
+	r     *bufio.Reader
+	state State
+	err   error
+}
+
+// NewReplay reads the header of a recording from r and returns a source that plays it back.
+func NewReplay(r io.Reader) (*Replay, error) {
+	p := &Replay{r: bufio.NewReader(r)}
+	magic := make([]byte, len(recordMagic))
+	if _, err := io.ReadFull(p.r, magic); err != nil || string(magic) != recordMagic {
+		return nil, errors.New("input: not a recording")
+	}
+	if v := p.uvarint(); p.err == nil && v != recordVersion {
+		return nil, fmt.Errorf("input: unsupported recording version %v", v)
+	}
+	p.Seed = p.varint()
+	p.Tick = time.Duration(p.varint())
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p, nil
+}
+
+// Poll gives the next recorded state.
+func (p *Replay) Poll(s *State) {
+	if p.Done() {
+		p.state = State{MouseX: p.state.MouseX, MouseY: p.state.MouseY}
+	} else {
+		p.read()
+	}
+	*s = p.state
+}
+
+// Done returns true once every recorded tick has been played back.
+func (p *Replay) Done() bool {
+	if p.err != nil {
+		return true
+	}
+	_, err := p.r.Peek(1)
+	return err != nil
+}
+
+// Err returns the error that ended the replay early, if any.
+func (p *Replay) Err() error {
+	return p.err
+}
+
+func (p *Replay) read() {
+	s := &p.state
+	n := p.uvarint()
+	for i := uint64(0); i < n && p.err == nil; i++ {
+		k := p.uvarint()
+		if k >= uint64(NumKeys) {
+			p.fail()
+			return
+		}
+		s.Keys[k] = !s.Keys[k]
+	}
+	buttons := p.byte()
+	for b := range s.MouseButtons {
+		s.MouseButtons[b] = buttons&(1<<uint(b)) != 0
+	}
+	s.MouseX += int(p.varint())
+	s.MouseY += int(p.varint())
+	present := p.byte()
+	for j := range s.Joysticks {
+		joy := &s.Joysticks[j]
+		joy.Present = present&(1<<uint(j)) != 0
+		pressed := p.uvarint()
+		for b := range joy.Buttons {
+			joy.Buttons[b] = pressed&(1<<uint(b)) != 0
+		}
+		moved := p.byte()
+		for a := range joy.Axes {
+			if moved&(1<<uint(a)) != 0 {
+				var buf [4]byte
+				if _, err := io.ReadFull(p.r, buf[:]); err != nil {
+					p.fail()
+					return
+				}
+				joy.Axes[a] = math.Float32frombits(binary.LittleEndian.Uint32(buf[:]))
+			}
+		}
+	}
+	if p.err != nil {
+		p.state = State{}
+	}
+}
+
+func (p *Replay) fail() {
+	if p.err == nil {
+		p.err = errors.New("input: corrupt recording")
+	}
+}
+
+func (p *Replay) byte() byte {
+	b, err := p.r.ReadByte()
+	if err != nil {
+		p.fail()
+	}
+	return b
+}
+
+func (p *Replay) uvarint() uint64 {
+	v, err := binary.ReadUvarint(p.r)
+	if err != nil {
+		p.fail()
+	}
+	return v
+}
+
+func (p *Replay) varint() int64 {
+	v, err := binary.ReadVarint(p.r)
+	if err != nil {
+		p.fail()
+	}
+	return v
+}
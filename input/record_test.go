@@ -0,0 +1,85 @@
+package input
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRecord(t *testing.T) {
+	script := new(Script)
+	script.Hold('A', 1, 3)
+	script.Hold(KeySpace, 2, 6)
+	script.HoldMouse(MouseLeft, 3, 4)
+	script.MoveMouse(120, -7, 4)
+	script.At(5).Joysticks[1].Present = true
+	script.At(5).Joysticks[1].Axes[2] = -0.75
+	script.At(5).Joysticks[1].Buttons[3] = true
+	var buf bytes.Buffer
+	r, err := NewRecorder(&buf, script, Header{Seed: -42, Tick: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected []State
+	for i := 0; i < 8; i++ {
+		var s State
+		r.Poll(&s)
+		expected = append(expected, s)
+	}
+	if err := r.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Seed != -42 || p.Tick != 10*time.Millisecond {
+		t.Errorf("Replay header is %+v, expected seed -42 and tick 10ms", p.Header)
+	}
+	for i, e := range expected {
+		if p.Done() {
+			t.Fatalf("Replay done after %v ticks, expected %v", i, len(expected))
+		}
+		var s State
+		p.Poll(&s)
+		if s != e {
+			t.Errorf("Tick %v: replayed %+v, expected %+v", i, s, e)
+		}
+	}
+	if !p.Done() || p.Err() != nil {
+		t.Errorf("Replay done %v with error %v at end, expected done without error", p.Done(), p.Err())
+	}
+	var s State
+	p.Poll(&s)
+	if s != (State{MouseX: 120, MouseY: -7}) {
+		t.Errorf("Polled %+v after end of replay, expected only the mouse position", s)
+	}
+}
+
+func TestCorruptRecording(t *testing.T) {
+	if _, err := NewReplay(bytes.NewReader([]byte("not a recording"))); err == nil {
+		t.Error("Replay of invalid data succeeded, expected error")
+	}
+	script := new(Script)
+	script.Hold('A', 0, 2)
+	var buf bytes.Buffer
+	r, err := NewRecorder(&buf, script, Header{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		r.Poll(new(State))
+	}
+	r.Flush()
+	p, err := NewReplay(bytes.NewReader(buf.Bytes()[:buf.Len()-3]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for !p.Done() {
+		p.Poll(new(State))
+	}
+	if p.Err() == nil {
+		t.Error("Replay of truncated recording succeeded, expected error")
+	}
+}
@@ -0,0 +1,61 @@
+package input
+
+// Script is a source that plays back a fixed sequence of states, one per poll, so that tests can
+// simulate input. Once the sequence runs out, all keys and buttons are released while the mouse
+// stays where it was.
+type Script struct {
+	States []State
+	polls  int
+}
+
+// Poll fills s with the next state in the script.
+func (s *Script) Poll(st *State) {
+	if s.polls < len(s.States) {
+		*st = s.States[s.polls]
+	} else {
+		*st = s.next()
+	}
+	s.polls++
+}
+
+// At returns the state the script will give on the numbered poll, counting from zero, extending
+// the script if necessary.
+func (s *Script) At(tick int) *State {
+	for len(s.States) <= tick {
+		s.States = append(s.States, s.next())
+	}
+	return &s.States[tick]
+}
+
+// next returns the state following the end of the script, with nothing held and the mouse
+// where it was last.
+func (s *Script) next() (st State) {
+	if n := len(s.States); n > 0 {
+		st.MouseX, st.MouseY = s.States[n-1].MouseX, s.States[n-1].MouseY
+	}
+	return st
+}
+
+// Hold holds the key down for the polls from start up to but not including end.
+func (s *Script) Hold(k Key, start, end int) {
+	s.At(end)
+	for i := start; i < end; i++ {
+		s.States[i].Keys[k] = true
+	}
+}
+
+// HoldMouse holds the mouse button down for the polls from start up to but not including end.
+func (s *Script) HoldMouse(b MouseButton, start, end int) {
+	s.At(end)
+	for i := start; i < end; i++ {
+		s.States[i].MouseButtons[b] = true
+	}
+}
+
+// MoveMouse moves the mouse to the given screen position from the numbered poll onwards.
+func (s *Script) MoveMouse(x, y int, start int) {
+	s.At(start)
+	for i := start; i < len(s.States); i++ {
+		s.States[i].MouseX, s.States[i].MouseY = x, y
+	}
+}
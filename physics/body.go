@@ -0,0 +1,72 @@
+// Package physics moves entities with velocities, and stops them passing through solid tiles and
+// each other, in the manner of a platform game.
+package physics
+
+import (
+	"github.com/FinnStokes/huge/camera"
+	"github.com/FinnStokes/huge/entity"
+)
+
+// Velocity is a component giving how fast an entity moves, in pixels per second.
+type Velocity struct {
+	X, Y float32
+}
+
+// Acceleration is a component giving how fast an entity's velocity changes, in pixels per second
+// squared, in addition to gravity.
+type Acceleration struct {
+	X, Y float32
+}
+
+// A Body is a component giving the axis aligned box that an entity occupies for the purposes of
+// physics. Only bodies whose entities have a velocity move.
+type Body struct {
+	// X and Y offset the top left corner of the box from the entity's position.
+	X, Y          float32
+	Width, Height float32
+	// Static bodies are never pushed or affected by gravity, but block other bodies like solid
+	// tiles. They still move with their velocity, if they have one, for use as moving platforms.
+	Static bool
+	// OneWay static bodies only block bodies landing on them from above, so that they can be
+	// jumped up through.
+	OneWay bool
+	// Mass decides how far each of two bodies is pushed when they overlap. A mass of zero is
+	// treated as one.
+	Mass float32
+	// Friction is how fast the body slows down while on the floor, in pixels per second squared.
+	Friction float32
+	// Weightless bodies are not affected by gravity.
+	Weightless bool
+	// Floor, Ceiling, Left and Right record which sides of the body were blocked at the last
+	// step, such as to check whether it is standing on something.
+	Floor, Ceiling, Left, Right bool
+}
+
+// Velocities returns the entity manager's store of velocities, registered as "velocity".
+func Velocities(m *entity.Manager) *entity.ComponentStore[*Velocity] {
+	return entity.Register[*Velocity](m, "velocity")
+}
+
+// Accelerations returns the entity manager's store of accelerations, registered as
+// "acceleration".
+func Accelerations(m *entity.Manager) *entity.ComponentStore[*Acceleration] {
+	return entity.Register[*Acceleration](m, "acceleration")
+}
+
+// Bodies returns the entity manager's store of bodies, registered as "body".
+func Bodies(m *entity.Manager) *entity.ComponentStore[*Body] {
+	return entity.Register[*Body](m, "body")
+}
+
+// Box returns the rectangle the body occupies when its entity is at x, y.
+func (b *Body) Box(x, y float32) camera.Rectangle {
+	return camera.Rectangle{X: x + b.X, Y: y + b.Y, Width: b.Width, Height: b.Height}
+}
+
+// mass returns the body's mass, applying the default.
+func (b *Body) mass() float32 {
+	if b.Mass == 0 {
+		return 1
+	}
+	return b.Mass
+}
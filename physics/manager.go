@@ -0,0 +1,383 @@
+package physics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/FinnStokes/huge/camera"
+	"github.com/FinnStokes/huge/collision"
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/render"
+)
+
+// skin is how far boxes may overlap along one axis while being moved along the other, so that
+// bodies resting on the floor can slide along it despite rounding errors.
+const skin = 1e-3
+
+// Manager is a system that moves the entities with pos, body and velocity components, in fixed
+// steps regardless of how often it is updated.
+//
+// Moving bodies are blocked by static bodies and by the tiles of tile maps whose properties mark
+// them as "solid", or as a one way "platform" that is only solid from above. Tiles with
+// "slopeLeft" and "slopeRight" properties are slopes, with the floor rising from the given height
+// in pixels above the bottom of the tile at its left edge to the given height at its right edge.
+// Bodies are lifted onto slopes, so they can walk up and down them, but are never blocked by
+// them from the side. Moving bodies that overlap each other are pushed apart according to their
+// masses. Bodies of entities whose collider is a trigger neither block nor push other bodies.
+type Manager struct {
+	// GravityX and GravityY accelerate every body that is not static or weightless, in pixels per
+	// second squared.
+	GravityX, GravityY float32
+	// Step is the interval of time simulated at once.
+	Step time.Duration
+	// SnapDistance is how far a body that was on the floor may drop onto the floor below it
+	// without losing contact, so that it stays on the ground while walking down slopes.
+	SnapDistance float32
+	pending      time.Duration
+	world        world
+	moving       []mover
+}
+
+// mover is a body moved during a step, along with its entity's components.
+type mover struct {
+	entity *entity.Entity
+	body   *Body
+	pos    *entity.Position
+	v      *Velocity
+	// trigger is true if the entity's collider is a trigger, which other bodies pass through.
+	trigger bool
+}
+
+func (m *mover) box() camera.Rectangle {
+	return m.body.Box(m.pos.X, m.pos.Y)
+}
+
+// NewManager returns an initialised physics manager with no gravity, stepping every 10ms.
+func NewManager() *Manager {
+	m := new(Manager)
+	m.Step = 10 * time.Millisecond
+	m.SnapDistance = 4
+	return m
+}
+
+// Update runs as many steps as fit in the time since the last update, carrying the remainder
+// forward to the next update.
+func (m *Manager) Update(dt time.Duration, entities *entity.Manager) {
+	m.pending += dt
+	for m.Step > 0 && m.pending >= m.Step {
+		m.pending -= m.Step
+		m.step(entities, float32(m.Step.Seconds()))
+	}
+}
+
+// Draw does nothing.
+func (m *Manager) Draw(r render.Renderer, c *camera.Camera, entities *entity.Manager) {}
+
+// step simulates dt seconds of motion.
+func (m *Manager) step(entities *entity.Manager, dt float32) {
+	bodies := Bodies(entities)
+	positions := entity.Positions(entities)
+	velocities := Velocities(entities)
+	accelerations := Accelerations(entities)
+
+	// Static bodies move first, so that the other bodies collide with them where they end up.
+	m.moving = m.moving[:0]
+	for _, e := range entities.View(entity.With("pos", "body", "velocity")).Entities() {
+		b, _ := bodies.Get(e)
+		pos, _ := positions.Get(e)
+		v, _ := velocities.Get(e)
+		if b.Static {
+			pos.X += v.X * dt
+			pos.Y += v.Y * dt
+			continue
+		}
+		m.moving = append(m.moving, mover{e, b, pos, v, trigger(entities, e)})
+	}
+	m.world.build(entities)
+
+	for _, mv := range m.moving {
+		b, v := mv.body, mv.v
+		if a, ok := accelerations.Get(mv.entity); ok {
+			v.X += a.X * dt
+			v.Y += a.Y * dt
+		}
+		if !b.Weightless {
+			v.X += m.GravityX * dt
+			v.Y += m.GravityY * dt
+		}
+		if b.Floor && b.Friction > 0 {
+			if slow := b.Friction * dt; v.X > slow {
+				v.X -= slow
+			} else if v.X < -slow {
+				v.X += slow
+			} else {
+				v.X = 0
+			}
+		}
+		m.move(b, mv.pos, v, dt)
+	}
+	m.separate()
+}
+
+// move moves the body horizontally and then vertically, stopping it at any obstacles.
+func (m *Manager) move(b *Body, pos *entity.Position, v *Velocity, dt float32) {
+	wasFloor := b.Floor
+	b.Floor, b.Ceiling, b.Left, b.Right = false, false, false, false
+
+	dx := v.X * dt
+	pos.X += dx
+	m.climb(b, pos)
+	if dx != 0 {
+		box := b.Box(pos.X, pos.Y)
+		box.Y += skin
+		box.Height -= 2 * skin
+		var limit float32
+		blocked := false
+		m.world.blockers(box, func(o obstacle) {
+			if o.oneWay {
+				return
+			}
+			if dx > 0 && (!blocked || o.box.X < limit) {
+				limit, blocked = o.box.X, true
+			} else if dx < 0 && (!blocked || o.box.X+o.box.Width > limit) {
+				limit, blocked = o.box.X+o.box.Width, true
+			}
+		})
+		if blocked && dx > 0 {
+			pos.X = limit - b.X - b.Width
+			b.Right = true
+			if v.X > 0 {
+				v.X = 0
+			}
+		} else if blocked {
+			pos.X = limit - b.X
+			b.Left = true
+			if v.X < 0 {
+				v.X = 0
+			}
+		}
+	}
+
+	dy := v.Y * dt
+	pos.Y += dy
+	if dy != 0 {
+		box := b.Box(pos.X, pos.Y)
+		box.X += skin
+		box.Width -= 2 * skin
+		bottom := box.Y + box.Height - dy
+		var limit float32
+		blocked := false
+		m.world.blockers(box, func(o obstacle) {
+			if dy > 0 && (!o.oneWay || bottom <= o.box.Y+skin) && (!blocked || o.box.Y < limit) {
+				limit, blocked = o.box.Y, true
+			} else if dy < 0 && !o.oneWay && (!blocked || o.box.Y+o.box.Height > limit) {
+				limit, blocked = o.box.Y+o.box.Height, true
+			}
+		})
+		if blocked && dy > 0 {
+			pos.Y = limit - b.Y - b.Height
+			b.Floor = true
+			if v.Y > 0 {
+				v.Y = 0
+			}
+		} else if blocked {
+			pos.Y = limit - b.Y
+			b.Ceiling = true
+			if v.Y < 0 {
+				v.Y = 0
+			}
+		}
+	}
+	if m.climb(b, pos) && v.Y >= 0 {
+		b.Floor = true
+		v.Y = 0
+	}
+
+	if wasFloor && !b.Floor && v.Y >= 0 && m.SnapDistance > 0 {
+		if drop, ok := m.drop(b, pos); ok {
+			pos.Y += drop
+			b.Floor = true
+			v.Y = 0
+		}
+	}
+}
+
+// drop returns how far the body is above the nearest floor or slope within SnapDistance below it.
+func (m *Manager) drop(b *Body, pos *entity.Position) (float32, bool) {
+	box := b.Box(pos.X, pos.Y)
+	box.X += skin
+	box.Width -= 2 * skin
+	bottom := box.Y + box.Height
+	box.Height += m.SnapDistance
+	drop, ok := m.SnapDistance, false
+	m.world.blockers(box, func(o obstacle) {
+		if d := o.box.Y - bottom; d >= -skin && d <= drop {
+			drop, ok = d, true
+		}
+	})
+	if surface, found := m.world.slope(box); found {
+		if d := surface - bottom; d >= -skin && d <= drop {
+			drop, ok = d, true
+		}
+	}
+	return drop, ok
+}
+
+// climb lifts the body onto the surface of any slope its bottom has sunk into, as long as no
+// more than half of it is below the surface, and returns true if it did.
+func (m *Manager) climb(b *Body, pos *entity.Position) bool {
+	box := b.Box(pos.X, pos.Y)
+	surface, ok := m.world.slope(box)
+	bottom := box.Y + box.Height
+	if !ok || bottom <= surface || bottom-surface > box.Height/2 {
+		return false
+	}
+	pos.Y -= bottom - surface
+	return true
+}
+
+// trigger returns true if the entity has a trigger collider.
+func trigger(entities *entity.Manager, e *entity.Entity) bool {
+	c, ok := collision.Colliders(entities).Get(e)
+	return ok && c.Trigger
+}
+
+// separate pushes apart the moving bodies that overlap, found by sweeping across them from left
+// to right.
+func (m *Manager) separate() {
+	sort.SliceStable(m.moving, func(i, j int) bool {
+		return m.moving[i].pos.X+m.moving[i].body.X < m.moving[j].pos.X+m.moving[j].body.X
+	})
+	for i := range m.moving {
+		a := &m.moving[i]
+		for j := i + 1; j < len(m.moving); j++ {
+			b := &m.moving[j]
+			ab, bb := a.box(), b.box()
+			if bb.X >= ab.X+ab.Width {
+				break
+			}
+			if !a.trigger && !b.trigger && ab.Intersects(&bb) {
+				m.push(a, b, ab, bb)
+			}
+		}
+	}
+}
+
+// push separates two overlapping bodies along the axis on which they overlap least, moving each
+// in inverse proportion to its mass. A body can only be pushed as far as the obstacles behind it
+// allow, with the rest of the push given to the other body, so neither is pushed into a wall. If
+// they are moving towards each other along that axis they are left moving together, conserving
+// momentum.
+func (m *Manager) push(a, b *mover, ab, bb camera.Rectangle) {
+	ma, mb := a.body.mass(), b.body.mass()
+	share := mb / (ma + mb)
+	ox := overlap(ab.X, ab.Width, bb.X, bb.Width)
+	oy := overlap(ab.Y, ab.Height, bb.Y, bb.Height)
+	if ox < oy {
+		dir := float32(1)
+		if ab.X+ab.Width/2 > bb.X+bb.Width/2 {
+			dir = -1
+		}
+		da, db := split(ox, share, m.room(ab, -dir, 0, ox), m.room(bb, dir, 0, ox))
+		a.pos.X -= dir * da
+		b.pos.X += dir * db
+		if (a.v.X-b.v.X)*dir > 0 {
+			v := (ma*a.v.X + mb*b.v.X) / (ma + mb)
+			a.v.X, b.v.X = v, v
+		}
+		if dir > 0 {
+			a.body.Right, b.body.Left = true, true
+		} else {
+			a.body.Left, b.body.Right = true, true
+		}
+		return
+	}
+	dir := float32(1)
+	if ab.Y+ab.Height/2 > bb.Y+bb.Height/2 {
+		dir = -1
+	}
+	da, db := split(oy, share, m.room(ab, 0, -dir, oy), m.room(bb, 0, dir, oy))
+	a.pos.Y -= dir * da
+	b.pos.Y += dir * db
+	if (a.v.Y-b.v.Y)*dir > 0 {
+		v := (ma*a.v.Y + mb*b.v.Y) / (ma + mb)
+		a.v.Y, b.v.Y = v, v
+	}
+	if dir > 0 {
+		a.body.Floor, b.body.Ceiling = true, true
+	} else {
+		a.body.Ceiling, b.body.Floor = true, true
+	}
+}
+
+// split divides the overlap o between two bodies, giving the first the given share of it, but
+// no more than the room each has to move, with what one cannot take given to the other.
+func split(o, share, roomA, roomB float32) (da, db float32) {
+	da = o * share
+	if da > roomA {
+		da = roomA
+	}
+	db = o - da
+	if db > roomB {
+		db = roomB
+		if da = o - db; da > roomA {
+			da = roomA
+		}
+	}
+	return da, db
+}
+
+// room returns how far the box can move in the direction (dx,dy), one of which is zero, before
+// it reaches an obstacle, up to a limit. One way platforms only stop boxes moving down onto them.
+func (m *Manager) room(box camera.Rectangle, dx, dy, limit float32) float32 {
+	sweep := box
+	switch {
+	case dx > 0:
+		sweep.X, sweep.Width = box.X+box.Width, limit
+	case dx < 0:
+		sweep.X, sweep.Width = box.X-limit, limit
+	case dy > 0:
+		sweep.Y, sweep.Height = box.Y+box.Height, limit
+	default:
+		sweep.Y, sweep.Height = box.Y-limit, limit
+	}
+	if dx != 0 {
+		sweep.Y += skin
+		sweep.Height -= 2 * skin
+	} else {
+		sweep.X += skin
+		sweep.Width -= 2 * skin
+	}
+	room := limit
+	m.world.blockers(sweep, func(o obstacle) {
+		if o.oneWay && (dy <= 0 || box.Y+box.Height > o.box.Y+skin) {
+			return
+		}
+		var gap float32
+		switch {
+		case dx > 0:
+			gap = o.box.X - box.X - box.Width
+		case dx < 0:
+			gap = box.X - o.box.X - o.box.Width
+		case dy > 0:
+			gap = o.box.Y - box.Y - box.Height
+		default:
+			gap = box.Y - o.box.Y - o.box.Height
+		}
+		if gap < 0 {
+			gap = 0
+		}
+		if gap < room {
+			room = gap
+		}
+	})
+	return room
+}
+
+// overlap returns how far the intervals starting at a and b with the given lengths overlap.
+func overlap(a, la, b, lb float32) float32 {
+	if d := a + la - b; d < b+lb-a {
+		return d
+	}
+	return b + lb - a
+}
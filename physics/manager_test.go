@@ -0,0 +1,213 @@
+package physics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/FinnStokes/huge/collision"
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/tilemap"
+)
+
+const (
+	solid    = 1
+	platform = 2
+	slope    = 3
+)
+
+// newLevel returns an entity manager holding a map of 8x8 tiles with a floor along the bottom
+// row, a slope up onto a raised floor to a wall on the right and a one way platform above.
+func newLevel() *entity.Manager {
+	tm := &tilemap.Map{
+		Width: 20, Height: 10, TileWidth: 8, TileHeight: 8,
+		Tilesets: []*tilemap.Tileset{{FirstGid: 1, TileWidth: 8, TileHeight: 8, Columns: 3, TileCount: 3, Tiles: map[int]tilemap.Properties{
+			0: {"solid": true},
+			1: {"platform": true},
+			2: {"slopeLeft": 0.0, "slopeRight": 8.0},
+		}}},
+	}
+	l := &tilemap.Layer{Type: tilemap.TileLayer, Tiles: make([]tilemap.Tile, 20*10)}
+	for x := 0; x < 20; x++ {
+		l.Tiles[9*20+x] = solid
+	}
+	for x := 11; x < 20; x++ {
+		l.Tiles[8*20+x] = solid
+	}
+	for y := 0; y < 10; y++ {
+		l.Tiles[y*20+15] = solid
+	}
+	l.Tiles[8*20+10] = slope
+	for x := 2; x < 5; x++ {
+		l.Tiles[5*20+x] = platform
+	}
+	tm.Layers = []*tilemap.Layer{l}
+	entities := entity.NewManager()
+	tilemap.Maps(entities).Set(entities.New(), tm)
+	return entities
+}
+
+func newBody(entities *entity.Manager, x, y, vx, vy float32, b *Body) (*entity.Position, *Velocity) {
+	e := entities.New()
+	pos := &entity.Position{X: x, Y: y}
+	v := &Velocity{X: vx, Y: vy}
+	entity.Positions(entities).Set(e, pos)
+	Velocities(entities).Set(e, v)
+	Bodies(entities).Set(e, b)
+	return pos, v
+}
+
+func run(m *Manager, entities *entity.Manager, d time.Duration) {
+	for i := time.Duration(0); i < d; i += 20 * time.Millisecond {
+		m.Update(20*time.Millisecond, entities)
+	}
+}
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-3
+}
+
+func TestFall(t *testing.T) {
+	entities := newLevel()
+	pos, v := newBody(entities, 4, 0, 0, 0, &Body{Width: 6, Height: 6})
+	m := NewManager()
+	m.GravityY = 600
+	run(m, entities, time.Second)
+	b, _ := Bodies(entities).Get(entities.View(entity.With("body")).Entities()[0])
+	if !near(pos.Y, 66) || v.Y != 0 || !b.Floor {
+		t.Errorf("Expected to land on floor, got y %v, velocity %v, floor %v", pos.Y, v.Y, b.Floor)
+	}
+}
+
+func TestFriction(t *testing.T) {
+	entities := newLevel()
+	pos, v := newBody(entities, 4, 66, 100, 0, &Body{Width: 6, Height: 6, Friction: 400})
+	m := NewManager()
+	m.GravityY = 600
+	run(m, entities, time.Second)
+	if v.X != 0 || pos.X < 15 || pos.X > 18 {
+		t.Errorf("Expected to slide to a stop after 12.5 pixels, got x %v, velocity %v", pos.X, v.X)
+	}
+}
+
+func TestSlope(t *testing.T) {
+	entities := newLevel()
+	b := &Body{Width: 6, Height: 6}
+	pos, v := newBody(entities, 70, 66, 0, 0, b)
+	m := NewManager()
+	m.GravityY = 600
+	for i := 0; i < 100; i++ {
+		v.X = 60
+		m.Update(10*time.Millisecond, entities)
+		if !b.Floor {
+			t.Fatalf("Left the ground at %v, %v", pos.X, pos.Y)
+		}
+		if right := pos.X + 6; right > 81 && right < 88 && !near(pos.Y+6, 72-(right-80)) {
+			t.Fatalf("Not on the slope at %v, %v", pos.X, pos.Y)
+		}
+	}
+	if !near(pos.X, 114) || !near(pos.Y, 58) || !b.Right {
+		t.Errorf("Expected to walk up the slope to the wall, got %v, %v", pos.X, pos.Y)
+	}
+
+	// Walking back down the slope stays on the ground.
+	for i := 0; i < 100; i++ {
+		v.X = -60
+		m.Update(10*time.Millisecond, entities)
+		if !b.Floor {
+			t.Fatalf("Left the ground at %v, %v", pos.X, pos.Y)
+		}
+	}
+	if !near(pos.Y, 66) {
+		t.Errorf("Expected to walk down the slope to the floor, got %v, %v", pos.X, pos.Y)
+	}
+}
+
+func TestPlatform(t *testing.T) {
+	entities := newLevel()
+	b := &Body{Width: 6, Height: 6}
+	pos, v := newBody(entities, 20, 66, 0, -300, b)
+	m := NewManager()
+	m.GravityY = 600
+	m.Update(100*time.Millisecond, entities)
+	if pos.Y > 40 {
+		t.Errorf("Expected to jump through the platform, got y %v", pos.Y)
+	}
+	run(m, entities, 2*time.Second)
+	if !near(pos.Y, 34) || v.Y != 0 || !b.Floor {
+		t.Errorf("Expected to land on the platform, got y %v, velocity %v, floor %v", pos.Y, v.Y, b.Floor)
+	}
+}
+
+func TestStatic(t *testing.T) {
+	entities := entity.NewManager()
+	wall := entities.New()
+	entity.Positions(entities).Set(wall, &entity.Position{X: 50})
+	Bodies(entities).Set(wall, &Body{Width: 10, Height: 100})
+	b := &Body{Width: 10, Height: 10}
+	pos, v := newBody(entities, 0, 0, 0, 0, b)
+	m := NewManager()
+	for i := 0; i < 50; i++ {
+		v.X = 100
+		m.Update(10*time.Millisecond, entities)
+	}
+	if !near(pos.X, 40) || v.X != 0 || !b.Right {
+		t.Errorf("Expected to stop at the wall, got x %v, velocity %v", pos.X, v.X)
+	}
+}
+
+func TestPush(t *testing.T) {
+	entities := entity.NewManager()
+	a := &Body{Width: 10, Height: 10}
+	pa, va := newBody(entities, 0, 0, 50, 0, a)
+	b := &Body{Width: 10, Height: 10, Mass: 3}
+	pb, vb := newBody(entities, 30, 2, -50, 0, b)
+	run(NewManager(), entities, time.Second)
+	if !near(va.X, -25) || !near(vb.X, -25) || va.Y != 0 || vb.Y != 0 {
+		t.Errorf("Expected bodies to move together at -25, got %v and %v", *va, *vb)
+	}
+	if !near(pb.X-pa.X, 10) {
+		t.Errorf("Expected bodies to be touching, got %v and %v", *pa, *pb)
+	}
+}
+
+func TestPushWall(t *testing.T) {
+	entities := entity.NewManager()
+	wall := entities.New()
+	entity.Positions(entities).Set(wall, &entity.Position{X: 50})
+	Bodies(entities).Set(wall, &Body{Width: 2, Height: 100})
+	pa, _ := newBody(entities, 38, 0, 0, 0, &Body{Width: 10, Height: 10})
+	pb, vb := newBody(entities, 20, 0, 0, 0, &Body{Width: 10, Height: 10, Mass: 50})
+	m := NewManager()
+	for i := 0; i < 20; i++ {
+		vb.X = 500
+		m.Update(10*time.Millisecond, entities)
+		if pa.X > 40+1e-3 || pb.X > pa.X-10+1e-3 {
+			t.Fatalf("Step %v: expected light body held against the wall, got %v and %v", i, *pa, *pb)
+		}
+	}
+}
+
+func TestTrigger(t *testing.T) {
+	entities := entity.NewManager()
+	colliders := collision.Colliders(entities)
+	checkpoint := entities.New()
+	entity.Positions(entities).Set(checkpoint, &entity.Position{X: 50})
+	Bodies(entities).Set(checkpoint, &Body{Width: 10, Height: 100})
+	colliders.Set(checkpoint, &collision.Collider{Width: 10, Height: 100, Trigger: true})
+	pickup := entities.New()
+	pp := &entity.Position{X: 80}
+	entity.Positions(entities).Set(pickup, pp)
+	Velocities(entities).Set(pickup, &Velocity{})
+	Bodies(entities).Set(pickup, &Body{Width: 10, Height: 10})
+	colliders.Set(pickup, &collision.Collider{Width: 10, Height: 10, Trigger: true})
+	pos, v := newBody(entities, 0, 0, 0, 0, &Body{Width: 10, Height: 10})
+	m := NewManager()
+	for i := 0; i < 100; i++ {
+		v.X = 100
+		m.Update(10*time.Millisecond, entities)
+	}
+	if !near(pos.X, 100) || !near(pp.X, 80) {
+		t.Errorf("Expected to pass through triggers, got x %v and pickup at %v", pos.X, pp.X)
+	}
+}
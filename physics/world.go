@@ -0,0 +1,129 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/FinnStokes/huge/camera"
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/tilemap"
+)
+
+// An obstacle is a static body or a solid tile.
+type obstacle struct {
+	box    camera.Rectangle
+	oneWay bool
+}
+
+// placedMap is a tile map placed in the world at the position of its entity.
+type placedMap struct {
+	tm   *tilemap.Map
+	x, y float32
+}
+
+// world holds everything that blocks moving bodies during a step.
+type world struct {
+	maps      []placedMap
+	obstacles []obstacle
+}
+
+// build gathers the tile maps and static bodies of the entities, leaving out triggers.
+func (w *world) build(entities *entity.Manager) {
+	positions := entity.Positions(entities)
+	maps := tilemap.Maps(entities)
+	w.maps = w.maps[:0]
+	for i, tm := range maps.Components() {
+		var x, y float32
+		if pos, ok := positions.Get(maps.Entities()[i]); ok {
+			x, y = pos.X, pos.Y
+		}
+		w.maps = append(w.maps, placedMap{tm, x, y})
+	}
+	bodies := Bodies(entities)
+	velocities := Velocities(entities)
+	w.obstacles = w.obstacles[:0]
+	for _, e := range entities.View(entity.With("pos", "body")).Entities() {
+		b, _ := bodies.Get(e)
+		if !b.Static && velocities.Has(e) || trigger(entities, e) {
+			continue
+		}
+		pos, _ := positions.Get(e)
+		w.obstacles = append(w.obstacles, obstacle{b.Box(pos.X, pos.Y), b.OneWay})
+	}
+}
+
+// blockers calls f for each static body and each solid or platform tile that overlaps the box.
+func (w *world) blockers(box camera.Rectangle, f func(o obstacle)) {
+	for _, o := range w.obstacles {
+		if o.box.Intersects(&box) {
+			f(o)
+		}
+	}
+	w.tiles(box, func(props tilemap.Properties, t tilemap.Tile, tile camera.Rectangle) {
+		switch {
+		case isSlope(props):
+		case props.Bool("solid"):
+			f(obstacle{box: tile})
+		case props.Bool("platform"):
+			f(obstacle{box: tile, oneWay: true})
+		}
+	})
+}
+
+// slope returns the highest point of the surfaces of the slope tiles overlapping the box, within
+// the box's horizontal extent.
+func (w *world) slope(box camera.Rectangle) (surface float32, ok bool) {
+	w.tiles(box, func(props tilemap.Properties, t tilemap.Tile, tile camera.Rectangle) {
+		if !isSlope(props) {
+			return
+		}
+		left, right := float32(props.Float("slopeLeft")), float32(props.Float("slopeRight"))
+		if t.FlipX() {
+			left, right = right, left
+		}
+		height := func(x float32) float32 {
+			return left + (right-left)*(x-tile.X)/tile.Width
+		}
+		h := height(float32(math.Max(float64(box.X), float64(tile.X))))
+		if h2 := height(float32(math.Min(float64(box.X+box.Width), float64(tile.X+tile.Width)))); h2 > h {
+			h = h2
+		}
+		if y := tile.Y + tile.Height - h; !ok || y < surface {
+			surface, ok = y, true
+		}
+	})
+	return surface, ok
+}
+
+// isSlope returns true if the tile properties describe a slope.
+func isSlope(props tilemap.Properties) bool {
+	_, left := props["slopeLeft"]
+	_, right := props["slopeRight"]
+	return left || right
+}
+
+// tiles calls f for each tile in the tile layers of the maps that overlaps the box.
+func (w *world) tiles(box camera.Rectangle, f func(props tilemap.Properties, t tilemap.Tile, tile camera.Rectangle)) {
+	for _, pm := range w.maps {
+		tm := pm.tm
+		tw, th := float32(tm.TileWidth), float32(tm.TileHeight)
+		for _, l := range tm.Layers {
+			if l.Type != tilemap.TileLayer {
+				continue
+			}
+			x, y := pm.x+l.OffsetX, pm.y+l.OffsetY
+			minCol, minRow := tm.Cell(box.X-x, box.Y-y)
+			maxCol := int(math.Ceil(float64((box.X+box.Width-x)/tw))) - 1
+			maxRow := int(math.Ceil(float64((box.Y+box.Height-y)/th))) - 1
+			for row := minRow; row <= maxRow; row++ {
+				for col := minCol; col <= maxCol; col++ {
+					t := tm.TileAt(l, col, row)
+					if t.Gid() == 0 {
+						continue
+					}
+					tile := camera.Rectangle{X: x + float32(col)*tw, Y: y + float32(row)*th, Width: tw, Height: th}
+					f(tm.TileProperties(t), t, tile)
+				}
+			}
+		}
+	}
+}
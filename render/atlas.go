@@ -0,0 +1,128 @@
+package render
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+)
+
+// An Atlas packs many small images onto a few large pages, so that sprites drawn from different
+// images can share a texture and be drawn in the same batch. Images are packed onto shelves as
+// they are added, and a new page is started whenever they no longer fit on an existing one.
+type Atlas struct {
+	// Width and Height are the size of each page in pixels.
+	Width, Height int
+	// Padding is the number of transparent pixels left between packed images, which stops
+	// neighbouring images bleeding into each other when they are sampled.
+	Padding int
+	pages   []*Page
+}
+
+// NewAtlas returns an empty atlas with pages of the given size and one pixel of padding.
+func NewAtlas(width, height int) *Atlas {
+	a := new(Atlas)
+	a.Width, a.Height = width, height
+	a.Padding = 1
+	return a
+}
+
+// A Page is one of the shared images of an atlas. Its version changes each time more images are
+// packed onto it, so that textures made from it can be updated.
+type Page struct {
+	*image.RGBA
+	shelves []shelf
+	version int
+}
+
+// Version returns a number that changes whenever the contents of the page change.
+func (p *Page) Version() int {
+	return p.version
+}
+
+// shelf is a row of a page that images are packed into from left to right.
+type shelf struct {
+	y, height, width int
+}
+
+// A Region is the part of an atlas page that an image was packed into.
+type Region struct {
+	Page   *Page
+	Bounds image.Rectangle
+}
+
+// Pages returns the pages of the atlas in the order they were created.
+func (a *Atlas) Pages() []*Page {
+	return a.pages
+}
+
+// Add packs img onto a page and returns the region it was copied into.
+func (a *Atlas) Add(img image.Image) (Region, error) {
+	regions, err := a.AddAll([]image.Image{img})
+	if err != nil {
+		return Region{}, err
+	}
+	return regions[0], nil
+}
+
+// AddAll packs all the images onto the same page, such as the frames of a sprite, and returns
+// the regions they were copied into in the same order.
+func (a *Atlas) AddAll(imgs []image.Image) ([]Region, error) {
+	for _, p := range a.pages {
+		if regions, ok := a.pack(p, imgs); ok {
+			return regions, nil
+		}
+	}
+	p := &Page{RGBA: image.NewRGBA(image.Rect(0, 0, a.Width, a.Height))}
+	regions, ok := a.pack(p, imgs)
+	if !ok {
+		return nil, errors.New("render: images too large for atlas page")
+	}
+	a.pages = append(a.pages, p)
+	return regions, nil
+}
+
+// pack copies the images onto p if they all fit, leaving the page untouched if they do not.
+func (a *Atlas) pack(p *Page, imgs []image.Image) ([]Region, bool) {
+	shelves := append([]shelf(nil), p.shelves...)
+	regions := make([]Region, len(imgs))
+	for i, img := range imgs {
+		w, h := img.Bounds().Dx(), img.Bounds().Dy()
+		pos, ok := a.place(&shelves, w+a.Padding, h+a.Padding)
+		if !ok {
+			return nil, false
+		}
+		regions[i] = Region{p, image.Rectangle{pos, pos.Add(image.Pt(w, h))}}
+	}
+	p.shelves = shelves
+	for i, img := range imgs {
+		draw.Draw(p.RGBA, regions[i].Bounds, img, img.Bounds().Min, draw.Src)
+	}
+	p.version++
+	return regions, true
+}
+
+// place finds room for a w by h rectangle, using the shortest shelf it fits on or starting a
+// new shelf below the others.
+func (a *Atlas) place(shelves *[]shelf, w, h int) (image.Point, bool) {
+	best := -1
+	for i, s := range *shelves {
+		if s.height >= h && s.width+w <= a.Width+a.Padding && (best < 0 || s.height < (*shelves)[best].height) {
+			best = i
+		}
+	}
+	if best < 0 {
+		y := 0
+		if n := len(*shelves); n > 0 {
+			y = (*shelves)[n-1].y + (*shelves)[n-1].height
+		}
+		if y+h > a.Height+a.Padding || w > a.Width+a.Padding {
+			return image.Point{}, false
+		}
+		*shelves = append(*shelves, shelf{y: y, height: h})
+		best = len(*shelves) - 1
+	}
+	s := &(*shelves)[best]
+	pos := image.Pt(s.width, s.y)
+	s.width += w
+	return pos, true
+}
@@ -0,0 +1,57 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func solid(w, h int, c color.RGBA) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestAtlas(t *testing.T) {
+	a := NewAtlas(64, 96)
+	var regions []Region
+	for i := 0; i < 12; i++ {
+		c := color.RGBA{uint8(i * 20), 0, 0, 255}
+		r, err := a.Add(solid(20, 10+i%3, c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Bounds.Dx() != 20 || r.Bounds.Dy() != 10+i%3 {
+			t.Errorf("Image %v packed into %v, expected size 20x%v", i, r.Bounds, 10+i%3)
+		}
+		if p := r.Page.RGBAAt(r.Bounds.Min.X, r.Bounds.Max.Y-1); p != c {
+			t.Errorf("Image %v packed as %v, expected %v", i, p, c)
+		}
+		for j, o := range regions {
+			if o.Page == r.Page && o.Bounds.Overlaps(r.Bounds.Inset(-1)) {
+				t.Errorf("Image %v at %v overlaps image %v at %v", i, r.Bounds, j, o.Bounds)
+			}
+		}
+		regions = append(regions, r)
+	}
+	if len(a.Pages()) != 1 {
+		t.Errorf("Atlas has %v pages, expected 1", len(a.Pages()))
+	}
+	version := a.Pages()[0].Version()
+	frames := []image.Image{solid(40, 40, color.RGBA{0, 255, 0, 255}), solid(20, 20, color.RGBA{0, 0, 255, 255})}
+	rs, err := a.AddAll(frames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Pages()) != 2 || rs[0].Page != a.Pages()[1] || rs[1].Page != a.Pages()[1] {
+		t.Errorf("Frames not packed together onto a new page")
+	}
+	if a.Pages()[0].Version() != version {
+		t.Errorf("Full page changed version")
+	}
+	if _, err := a.Add(solid(65, 10, color.RGBA{})); err == nil {
+		t.Errorf("Packed image wider than page, expected error")
+	}
+}
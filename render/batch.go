@@ -0,0 +1,49 @@
+package render
+
+// A Batch collects textured quads over the course of a frame so that every quad sharing a
+// texture can be drawn with a single call to the renderer. Quads using the same texture are
+// drawn in the order they were added, and textures are drawn in the order they were first used.
+type Batch struct {
+	textures []Texture
+	vertices map[Texture][]Vertex
+}
+
+// NewBatch returns an empty batch.
+func NewBatch() *Batch {
+	b := new(Batch)
+	b.vertices = make(map[Texture][]Vertex)
+	return b
+}
+
+// Add queues a quad to be drawn with the given texture.
+func (b *Batch) Add(tex Texture, quad [4]Vertex) {
+	v, ok := b.vertices[tex]
+	if !ok || len(v) == 0 {
+		b.textures = append(b.textures, tex)
+	}
+	b.vertices[tex] = append(v, quad[:]...)
+}
+
+// Len returns the number of quads waiting to be drawn.
+func (b *Batch) Len() int {
+	n := 0
+	for _, tex := range b.textures {
+		n += len(b.vertices[tex]) / 4
+	}
+	return n
+}
+
+// Draw draws all the queued quads, making one call to r for each texture, and empties the batch.
+// The memory used by the batch is kept for the next frame.
+func (b *Batch) Draw(r Renderer) {
+	for _, tex := range b.textures {
+		r.DrawQuads(tex, b.vertices[tex])
+		b.vertices[tex] = b.vertices[tex][:0]
+	}
+	b.textures = b.textures[:0]
+}
+
+// Forget drops the memory kept for the texture, which should be called when it is released.
+func (b *Batch) Forget(tex Texture) {
+	delete(b.vertices, tex)
+}
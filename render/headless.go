@@ -0,0 +1,290 @@
+package render
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Headless is a window and renderer that draws into an in-memory image using only the CPU, so
+// that games can be run and tested without a display or GPU.
+type Headless struct {
+	width, height int
+	back, front   *image.RGBA
+	viewport      image.Rectangle
+	resize        func(w, h int)
+	open          bool
+}
+
+// NewHeadless returns a headless window which will open with the given size, ignoring the
+// size requested by the game.
+func NewHeadless(width, height int) *Headless {
+	h := new(Headless)
+	h.width, h.height = width, height
+	return h
+}
+
+// Open allocates the window's framebuffers.
+func (h *Headless) Open(width, height int, title string) error {
+	if h.open {
+		return errors.New("render: headless window already open")
+	}
+	if h.width <= 0 || h.height <= 0 {
+		h.width, h.height = width, height
+	}
+	h.back = image.NewRGBA(image.Rect(0, 0, h.width, h.height))
+	h.front = image.NewRGBA(image.Rect(0, 0, h.width, h.height))
+	h.open = true
+	if h.resize != nil {
+		h.resize(h.width, h.height)
+	}
+	return nil
+}
+
+// Close closes the window. The last presented frame remains available from Image.
+func (h *Headless) Close() {
+	h.open = false
+}
+
+// IsOpen returns true if the window has been opened and not yet closed.
+func (h *Headless) IsOpen() bool {
+	return h.open
+}
+
+// Size returns the dimensions of the window in pixels.
+func (h *Headless) Size() (width, height int) {
+	return h.width, h.height
+}
+
+// SetResizeCallback sets a function to be called whenever the window changes size.
+func (h *Headless) SetResizeCallback(f func(width, height int)) {
+	h.resize = f
+	if h.open && f != nil {
+		f(h.width, h.height)
+	}
+}
+
+// Resize simulates the window being resized by the user, reallocating the framebuffers.
+func (h *Headless) Resize(width, height int) {
+	h.width, h.height = width, height
+	if h.open {
+		h.back = image.NewRGBA(image.Rect(0, 0, width, height))
+		h.front = image.NewRGBA(image.Rect(0, 0, width, height))
+		if h.resize != nil {
+			h.resize(width, height)
+		}
+	}
+}
+
+// SwapBuffers presents the frame drawn since the last call.
+func (h *Headless) SwapBuffers() {
+	if h.open {
+		copy(h.front.Pix, h.back.Pix)
+	}
+}
+
+// Image returns the most recently presented frame.
+func (h *Headless) Image() *image.RGBA {
+	return h.front
+}
+
+// Renderer returns the window itself, which draws into its back buffer.
+func (h *Headless) Renderer() Renderer {
+	return h
+}
+
+// SetViewport limits drawing to a rectangle of the back buffer.
+func (h *Headless) SetViewport(x, y, width, height int) {
+	if width <= 0 || height <= 0 {
+		h.viewport = image.Rectangle{}
+		return
+	}
+	h.viewport = image.Rect(x, y, x+width, y+height)
+}
+
+// Clear fills the viewport with a single colour.
+func (h *Headless) Clear(r, g, b, a float32) {
+	c := color.NRGBA{channel(r), channel(g), channel(b), channel(a)}
+	draw.Draw(h.back, h.clip(), image.NewUniform(c), image.ZP, draw.Src)
+}
+
+// clip returns the part of the back buffer that can be drawn to.
+func (h *Headless) clip() image.Rectangle {
+	if h.viewport.Empty() {
+		return h.back.Bounds()
+	}
+	return h.viewport.Intersect(h.back.Bounds())
+}
+
+// NewTexture converts img for fast sampling by the software rasteriser.
+func (h *Headless) NewTexture(img image.Image) Texture {
+	b := img.Bounds()
+	t := &headlessTexture{image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))}
+	draw.Draw(t.img, t.img.Bounds(), img, b.Min, draw.Src)
+	return t
+}
+
+// FillRect blends a rectangle of a single colour over the back buffer.
+func (h *Headless) FillRect(x, y, width, height float32, r, g, b, a float32) {
+	if !h.open {
+		return
+	}
+	rect := image.Rect(int(x+0.5), int(y+0.5), int(x+width+0.5), int(y+height+0.5)).Add(h.viewport.Min)
+	c := color.NRGBA{channel(r), channel(g), channel(b), channel(a)}
+	draw.Draw(h.back, rect.Intersect(h.clip()), image.NewUniform(c), image.ZP, draw.Over)
+}
+
+// DrawQuad rasterises the quad as two triangles, sampling the nearest texel and blending it
+// over the back buffer.
+func (h *Headless) DrawQuad(tex Texture, quad [4]Vertex) {
+	t, ok := tex.(*headlessTexture)
+	if !ok || !h.open {
+		return
+	}
+	h.triangle(t, quad[0], quad[1], quad[2], false)
+	h.triangle(t, quad[0], quad[2], quad[3], true)
+}
+
+// DrawQuads rasterises each group of four vertices as a quad, in order.
+func (h *Headless) DrawQuads(tex Texture, quads []Vertex) {
+	t, ok := tex.(*headlessTexture)
+	if !ok || !h.open {
+		return
+	}
+	for i := 0; i+3 < len(quads); i += 4 {
+		h.triangle(t, quads[i], quads[i+1], quads[i+2], false)
+		h.triangle(t, quads[i], quads[i+2], quads[i+3], true)
+	}
+}
+
+// triangle fills the triangle abc. If shared is true, pixels lying exactly on the edge ab are
+// skipped, as they have already been drawn as part of the neighbouring triangle.
+func (h *Headless) triangle(t *headlessTexture, a, b, c Vertex, shared bool) {
+	area := edge(a, b, c.X, c.Y)
+	if area == 0 {
+		return
+	}
+	for _, v := range []*Vertex{&a, &b, &c} {
+		v.X += float32(h.viewport.Min.X)
+		v.Y += float32(h.viewport.Min.Y)
+	}
+	minX := int(math.Floor(float64(min3(a.X, b.X, c.X))))
+	maxX := int(math.Ceil(float64(max3(a.X, b.X, c.X))))
+	minY := int(math.Floor(float64(min3(a.Y, b.Y, c.Y))))
+	maxY := int(math.Ceil(float64(max3(a.Y, b.Y, c.Y))))
+	bounds := h.clip()
+	if minX < bounds.Min.X {
+		minX = bounds.Min.X
+	}
+	if minY < bounds.Min.Y {
+		minY = bounds.Min.Y
+	}
+	if maxX > bounds.Max.X {
+		maxX = bounds.Max.X
+	}
+	if maxY > bounds.Max.Y {
+		maxY = bounds.Max.Y
+	}
+	tw, th := t.img.Bounds().Dx(), t.img.Bounds().Dy()
+	for y := minY; y < maxY; y++ {
+		py := float32(y) + 0.5
+		for x := minX; x < maxX; x++ {
+			px := float32(x) + 0.5
+			w0 := edge(b, c, px, py) / area
+			w1 := edge(c, a, px, py) / area
+			w2 := edge(a, b, px, py) / area
+			if w0 < 0 || w1 < 0 || w2 < 0 || (shared && w2 == 0) {
+				continue
+			}
+			u := w0*a.U + w1*b.U + w2*c.U
+			v := w0*a.V + w1*b.V + w2*c.V
+			tx := clamp(int(u*float32(tw)), tw)
+			ty := clamp(int(v*float32(th)), th)
+			tint := [4]float32{
+				w0*a.R + w1*b.R + w2*c.R,
+				w0*a.G + w1*b.G + w2*c.G,
+				w0*a.B + w1*b.B + w2*c.B,
+				w0*a.A + w1*b.A + w2*c.A,
+			}
+			blend(h.back, x, y, t.img, tx, ty, tint)
+		}
+	}
+}
+
+type headlessTexture struct {
+	img *image.RGBA
+}
+
+func (t *headlessTexture) Size() (width, height int) {
+	return t.img.Bounds().Dx(), t.img.Bounds().Dy()
+}
+
+func (t *headlessTexture) Release() {}
+
+// blend tints the premultiplied source texel with the unpremultiplied tint and composites it
+// over the destination pixel.
+func blend(dst *image.RGBA, x, y int, src *image.RGBA, sx, sy int, tint [4]float32) {
+	s := src.Pix[src.PixOffset(sx, sy) : src.PixOffset(sx, sy)+4]
+	var t [4]uint32
+	for i := range t {
+		t[i] = uint32(channel(tint[i]))
+	}
+	var c [4]uint32
+	for i := 0; i < 3; i++ {
+		c[i] = uint32(s[i]) * t[i] * t[3] / (255 * 255)
+	}
+	c[3] = uint32(s[3]) * t[3] / 255
+	if c[3] == 0 {
+		return
+	}
+	d := dst.Pix[dst.PixOffset(x, y):]
+	for i := 0; i < 4; i++ {
+		d[i] = uint8(c[i] + uint32(d[i])*(255-c[3])/255)
+	}
+}
+
+func edge(a, b Vertex, x, y float32) float32 {
+	return (b.X-a.X)*(y-a.Y) - (b.Y-a.Y)*(x-a.X)
+}
+
+func channel(v float32) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 1 {
+		return 255
+	}
+	return uint8(v*255 + 0.5)
+}
+
+func clamp(v, n int) int {
+	if v < 0 {
+		return 0
+	}
+	if v >= n {
+		return n - 1
+	}
+	return v
+}
+
+func min3(a, b, c float32) float32 {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func max3(a, b, c float32) float32 {
+	if b > a {
+		a = b
+	}
+	if c > a {
+		a = c
+	}
+	return a
+}
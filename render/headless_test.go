@@ -0,0 +1,32 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestTint(t *testing.T) {
+	h := NewHeadless(4, 2)
+	h.Open(4, 2, "")
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, color.RGBA{255, 255, 255, 255})
+	tex := h.NewTexture(img)
+	quad := func(x float32, r, g, b, a float32) [4]Vertex {
+		var q [4]Vertex
+		for i, c := range [4][2]float32{{0, 0}, {2, 0}, {2, 2}, {0, 2}} {
+			q[i] = Vertex{X: x + c[0], Y: c[1], U: c[0] / 2, V: c[1] / 2, R: r, G: g, B: b, A: a}
+		}
+		return q
+	}
+	h.DrawQuad(tex, quad(0, 1, 1, 1, 1))
+	h.DrawQuad(tex, quad(2, 1, 0, 0, 1))
+	h.DrawQuad(tex, quad(2, 0, 0, 0, 0))
+	h.SwapBuffers()
+	if p := h.Image().RGBAAt(1, 1); p != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("White vertices drew %v, expected the untinted texture", p)
+	}
+	if p := h.Image().RGBAAt(3, 1); p != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("Red vertices drew %v, expected red unchanged by vertices without colour", p)
+	}
+}
@@ -0,0 +1,73 @@
+package opengl
+
+import (
+	"github.com/FinnStokes/huge/input"
+
+	"github.com/go-gl/glfw"
+)
+
+// specialKeys maps the non-printable keys to their GLFW codes. Printable keys share their codes
+// with GLFW.
+var specialKeys = map[input.Key]int{
+	input.KeyEscape:     glfw.KeyEsc,
+	input.KeyEnter:      glfw.KeyEnter,
+	input.KeyTab:        glfw.KeyTab,
+	input.KeyBackspace:  glfw.KeyBackspace,
+	input.KeyInsert:     glfw.KeyInsert,
+	input.KeyDelete:     glfw.KeyDel,
+	input.KeyHome:       glfw.KeyHome,
+	input.KeyEnd:        glfw.KeyEnd,
+	input.KeyPageUp:     glfw.KeyPageup,
+	input.KeyPageDown:   glfw.KeyPagedown,
+	input.KeyUp:         glfw.KeyUp,
+	input.KeyDown:       glfw.KeyDown,
+	input.KeyLeft:       glfw.KeyLeft,
+	input.KeyRight:      glfw.KeyRight,
+	input.KeyLeftShift:  glfw.KeyLshift,
+	input.KeyRightShift: glfw.KeyRshift,
+	input.KeyLeftCtrl:   glfw.KeyLctrl,
+	input.KeyRightCtrl:  glfw.KeyRctrl,
+	input.KeyLeftAlt:    glfw.KeyLalt,
+	input.KeyRightAlt:   glfw.KeyRalt,
+}
+
+var mouseButtons = [input.NumMouseButtons]int{
+	input.MouseLeft:   glfw.MouseLeft,
+	input.MouseRight:  glfw.MouseRight,
+	input.MouseMiddle: glfw.MouseMiddle,
+}
+
+func init() {
+	for k := input.KeyF1; k <= input.KeyF12; k++ {
+		specialKeys[k] = glfw.KeyF1 + int(k-input.KeyF1)
+	}
+}
+
+// Poll reads the current state of the keyboard, mouse and joysticks, making the window an
+// input.Source.
+func (w *Window) Poll(s *input.State) {
+	*s = input.State{}
+	for k := input.KeySpace; k < input.KeySpecial; k++ {
+		s.Keys[k] = glfw.Key(int(k)) == glfw.KeyPress
+	}
+	for k, code := range specialKeys {
+		s.Keys[k] = glfw.Key(code) == glfw.KeyPress
+	}
+	for b, code := range mouseButtons {
+		s.MouseButtons[b] = glfw.MouseButton(code) == glfw.KeyPress
+	}
+	s.MouseX, s.MouseY = glfw.MousePos()
+	var buttons [input.MaxButtons]byte
+	for j := range s.Joysticks {
+		joy := &s.Joysticks[j]
+		joy.Present = glfw.JoystickParam(glfw.Joy1+j, glfw.Present) == 1
+		if !joy.Present {
+			continue
+		}
+		glfw.JoystickPos(glfw.Joy1+j, joy.Axes[:])
+		n := glfw.JoystickButtons(glfw.Joy1+j, buttons[:])
+		for b := 0; b < n; b++ {
+			joy.Buttons[b] = buttons[b] == glfw.KeyPress
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Package opengl implements the render interfaces using GLFW for windowing and fixed function
+// OpenGL for drawing.
+package opengl
+
+import (
+	"image"
+	"unsafe"
+
+	"github.com/FinnStokes/huge/render"
+
+	"github.com/go-gl/gl"
+	"github.com/go-gl/glfw"
+)
+
+// Window is a GLFW window with an OpenGL context. Only one window may be open at a time.
+type Window struct {
+	renderer Renderer
+	resize   func(w, h int)
+}
+
+// NewWindow returns an unopened window.
+func NewWindow() *Window {
+	return new(Window)
+}
+
+// Open initialises GLFW and opens a window of the given size.
+func (w *Window) Open(width, height int, title string) error {
+	if err := glfw.Init(); err != nil {
+		return err
+	}
+	if err := glfw.OpenWindow(width, height, 16, 16, 16, 16, 0, 0, glfw.Windowed); err != nil {
+		glfw.Terminate()
+		return err
+	}
+	glfw.SetWindowTitle(title)
+	glfw.SetSwapInterval(1)
+	glfw.SetWindowSizeCallback(func(width, height int) { w.onResize(width, height) })
+	return nil
+}
+
+// Close closes the window and terminates GLFW.
+func (w *Window) Close() {
+	glfw.CloseWindow()
+	glfw.Terminate()
+}
+
+// IsOpen returns true until the window is closed, either by the game or the user.
+func (w *Window) IsOpen() bool {
+	return glfw.WindowParam(glfw.Opened) == 1
+}
+
+// Size returns the dimensions of the window in pixels.
+func (w *Window) Size() (width, height int) {
+	return glfw.WindowSize()
+}
+
+// SetResizeCallback sets a function to be called whenever the window changes size.
+func (w *Window) SetResizeCallback(f func(width, height int)) {
+	w.resize = f
+}
+
+// SwapBuffers presents the frame drawn since the last call.
+func (w *Window) SwapBuffers() {
+	glfw.SwapBuffers()
+}
+
+// Renderer returns the OpenGL renderer for the window.
+func (w *Window) Renderer() render.Renderer {
+	return &w.renderer
+}
+
+func (w *Window) onResize(width, height int) {
+	w.renderer.width, w.renderer.height = width, height
+	w.renderer.SetViewport(0, 0, 0, 0)
+	if w.resize != nil {
+		w.resize(width, height)
+	}
+}
+
+// Renderer draws using OpenGL. Quads are streamed through a vertex buffer so that each call to
+// DrawQuads is a single draw call however many quads it contains.
+type Renderer struct {
+	buffer        gl.Buffer
+	width, height int
+}
+
+// SetViewport limits drawing to a rectangle of the window, with the origin at its top left
+// corner.
+func (r *Renderer) SetViewport(x, y, width, height int) {
+	if width <= 0 || height <= 0 {
+		x, y, width, height = 0, 0, r.width, r.height
+		gl.Disable(gl.SCISSOR_TEST)
+	} else {
+		gl.Enable(gl.SCISSOR_TEST)
+	}
+	// OpenGL places the origin of the window at its bottom left corner.
+	gl.Viewport(x, r.height-y-height, width, height)
+	gl.Scissor(x, r.height-y-height, width, height)
+	gl.MatrixMode(gl.PROJECTION)
+	gl.LoadIdentity()
+	gl.Ortho(0, float64(width), float64(height), 0, -1.0, 1.0)
+	gl.MatrixMode(gl.MODELVIEW)
+	gl.LoadIdentity()
+}
+
+// Clear fills the viewport with a single colour.
+func (r *Renderer) Clear(red, green, blue, alpha float32) {
+	gl.ClearColor(gl.GLclampf(red), gl.GLclampf(green), gl.GLclampf(blue), gl.GLclampf(alpha))
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+}
+
+// NewTexture uploads img to a new OpenGL texture.
+func (r *Renderer) NewTexture(img image.Image) render.Texture {
+	return &Texture{loadImageAsTexture(img), img.Bounds().Dx(), img.Bounds().Dy()}
+}
+
+// DrawQuad draws a textured quad with alpha blending.
+func (r *Renderer) DrawQuad(tex render.Texture, quad [4]render.Vertex) {
+	r.DrawQuads(tex, quad[:])
+}
+
+// DrawQuads draws textured quads, tinted by their vertex colours, with alpha blending in a single
+// draw call.
+func (r *Renderer) DrawQuads(tex render.Texture, quads []render.Vertex) {
+	t, ok := tex.(*Texture)
+	if !ok || len(quads) < 4 {
+		return
+	}
+	if r.buffer == 0 {
+		r.buffer = gl.GenBuffer()
+	}
+	gl.Enable(gl.BLEND)
+	gl.Disable(gl.LIGHTING)
+	gl.TexEnvf(gl.TEXTURE_ENV, gl.TEXTURE_ENV_MODE, gl.MODULATE)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.Enable(gl.TEXTURE_2D)
+
+	t.tex.Bind(gl.TEXTURE_2D)
+	r.buffer.Bind(gl.ARRAY_BUFFER)
+	gl.BufferData(gl.ARRAY_BUFFER, len(quads)*int(unsafe.Sizeof(quads[0])), quads, gl.STREAM_DRAW)
+	gl.EnableClientState(gl.VERTEX_ARRAY)
+	gl.EnableClientState(gl.TEXTURE_COORD_ARRAY)
+	gl.EnableClientState(gl.COLOR_ARRAY)
+	stride := int(unsafe.Sizeof(quads[0]))
+	gl.VertexPointer(2, gl.FLOAT, stride, uintptr(unsafe.Offsetof(quads[0].X)))
+	gl.TexCoordPointer(2, gl.FLOAT, stride, uintptr(unsafe.Offsetof(quads[0].U)))
+	gl.ColorPointer(4, gl.FLOAT, stride, uintptr(unsafe.Offsetof(quads[0].R)))
+	gl.DrawArrays(gl.QUADS, 0, len(quads)/4*4)
+	gl.DisableClientState(gl.COLOR_ARRAY)
+	gl.DisableClientState(gl.TEXTURE_COORD_ARRAY)
+	gl.DisableClientState(gl.VERTEX_ARRAY)
+	r.buffer.Unbind(gl.ARRAY_BUFFER)
+	t.tex.Unbind(gl.TEXTURE_2D)
+}
+
+// FillRect draws an untextured rectangle of a single colour with alpha blending.
+func (r *Renderer) FillRect(x, y, width, height float32, red, green, blue, alpha float32) {
+	gl.Enable(gl.BLEND)
+	gl.Disable(gl.TEXTURE_2D)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.Color4f(red, green, blue, alpha)
+	gl.Begin(gl.QUADS)
+	gl.Vertex2f(x, y)
+	gl.Vertex2f(x+width, y)
+	gl.Vertex2f(x+width, y+height)
+	gl.Vertex2f(x, y+height)
+	gl.End()
+}
+
+// Texture is an image stored in video memory.
+type Texture struct {
+	tex           gl.Texture
+	width, height int
+}
+
+// Size returns the dimensions of the texture in pixels.
+func (t *Texture) Size() (width, height int) {
+	return t.width, t.height
+}
+
+// Release frees the video memory used by the texture.
+func (t *Texture) Release() {
+	t.tex.Delete()
+}
+
+type rgba struct {
+	r, g, b, a uint16
+}
+
+func loadImageAsTexture(img image.Image) gl.Texture {
+	tex := gl.GenTexture()
+	tex.Bind(gl.TEXTURE_2D)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	bounds := img.Bounds()
+	w := bounds.Dx()
+	h := bounds.Dy()
+	data := make([]rgba, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			data[x+y*w].r = uint16(r)
+			data[x+y*w].g = uint16(g)
+			data[x+y*w].b = uint16(b)
+			data[x+y*w].a = uint16(a)
+		}
+	}
+	gl.TexImage2D(
+		gl.TEXTURE_2D,     // target
+		0,                 // level, 0 = base, no mipmap,
+		gl.RGBA,           // internal format
+		w,                 // width
+		h,                 // height
+		0,                 // border
+		gl.RGBA,           // format
+		gl.UNSIGNED_SHORT, // type
+		data,              // image
+	)
+	tex.Unbind(gl.TEXTURE_2D)
+	return tex
+}
@@ -0,0 +1,51 @@
+// Package render defines the window and drawing interfaces used by the game, allowing the
+// platform backend to be swapped out, and provides a headless software implementation.
+package render
+
+import "image"
+
+// A Window is an on-screen or off-screen surface that a game is drawn into. Drawing is
+// performed through the Renderer, and the result is presented by calling SwapBuffers.
+type Window interface {
+	Open(width, height int, title string) error
+	Close()
+	IsOpen() bool
+	Size() (width, height int)
+	SetResizeCallback(f func(width, height int))
+	SwapBuffers()
+	Renderer() Renderer
+}
+
+// A Renderer draws textured geometry into a window. Coordinates are given in screen pixels
+// with the origin at the top left corner of the viewport, which is the whole window unless set
+// otherwise by SetViewport. DrawQuads draws many quads with a single texture at once, taking
+// their vertices in groups of four.
+type Renderer interface {
+	// SetViewport limits drawing to a rectangle of the window, given in pixels from its top left
+	// corner, and moves the origin to the rectangle's top left corner. A viewport with no area
+	// covers the whole window.
+	SetViewport(x, y, width, height int)
+	// Clear fills the viewport with a single colour.
+	Clear(r, g, b, a float32)
+	NewTexture(img image.Image) Texture
+	DrawQuad(tex Texture, quad [4]Vertex)
+	DrawQuads(tex Texture, quads []Vertex)
+	FillRect(x, y, width, height float32, r, g, b, a float32)
+}
+
+// A Texture is an image that has been uploaded for drawing by a renderer.
+type Texture interface {
+	Size() (width, height int)
+	Release()
+}
+
+// A Vertex is a corner of a drawn polygon. X and Y are in screen pixels while U and V are
+// texture coordinates, ranging from 0 to 1 across the texture. The texture is tinted by
+// multiplying it with the colour R, G, B and A, so a white vertex draws the texture unchanged.
+// Every vertex must be given a colour, as one left with the zero colour is transparent black and
+// draws nothing.
+type Vertex struct {
+	X, Y       float32
+	U, V       float32
+	R, G, B, A float32
+}
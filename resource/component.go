@@ -0,0 +1,74 @@
+package resource
+
+import (
+	"encoding/json"
+
+	"github.com/FinnStokes/huge/collision"
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/physics"
+	"github.com/FinnStokes/huge/sprite"
+	"github.com/FinnStokes/huge/tilemap"
+)
+
+// RegisterComponents registers the position, transform, sprite, depth, sound, tilemap, object,
+// collider, velocity, acceleration and body components with the given entity manager, using
+// codecs that load resources through m, followed by any components added with AddComponents, so
+// that they can be saved, loaded and spawned from prefabs and scenes.
+func (m *Manager) RegisterComponents(entities *entity.Manager) {
+	entity.Positions(entities)
+	entity.Transforms(entities)
+	sprite.Sprites(entities).SetCodec(m.SpriteCodec())
+	sprite.Depths(entities)
+	Sounds(entities).SetCodec(m.SoundCodec())
+	tilemap.Maps(entities).SetCodec(m.TilemapCodec())
+	tilemap.Objects(entities)
+	collision.Colliders(entities)
+	physics.Velocities(entities)
+	physics.Accelerations(entities)
+	physics.Bodies(entities)
+	for _, register := range m.components {
+		register(entities)
+	}
+}
+
+// AddComponents adds a function to be called by RegisterComponents, which registers a game's own
+// component types with each entity manager that the resource manager sets up.
+func (m *Manager) AddComponents(register func(entities *entity.Manager)) {
+	m.components = append(m.components, register)
+}
+
+// Sounds returns the entity manager's store of sound components, registered as "sound".
+func Sounds(m *entity.Manager) *entity.ComponentStore[*Sound] {
+	return entity.Register[*Sound](m, "sound")
+}
+
+// soundState is the saved form of a sound, referring to its resource by name.
+type soundState struct {
+	Name  string
+	Music bool `json:",omitempty"`
+}
+
+type soundCodec struct {
+	m *Manager
+}
+
+// SoundCodec returns a codec that saves sounds by the name of the resource they were loaded
+// from, and loads them through the manager.
+func (m *Manager) SoundCodec() entity.Codec[*Sound] {
+	return soundCodec{m}
+}
+
+func (c soundCodec) Marshal(s *Sound) ([]byte, error) {
+	return json.Marshal(&soundState{s.name, s.music})
+}
+
+func (c soundCodec) Unmarshal(data []byte) (*Sound, error) {
+	var state soundState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	if state.Music {
+		return c.m.GetMusic(state.Name)
+	}
+	return c.m.GetSound(state.Name)
+}
@@ -0,0 +1,17 @@
+package resource
+
+import "github.com/FinnStokes/huge/input"
+
+// GetBindings fetches a mapping of action names to inputs from a .json file, as in
+// {"jump": [{"key": "space"}, {"joystick": 0, "button": 0}], "fire": [{"mouse": "left"}]}
+func (m *Manager) GetBindings(name string) (input.Bindings, error) {
+	b, ok := m.bindings[name]
+	if !ok {
+		b = make(input.Bindings)
+		if err := m.GetJson(name, &b); err != nil {
+			return nil, err
+		}
+		m.bindings[name] = b
+	}
+	return b, nil
+}
@@ -7,16 +7,31 @@ import (
 	_ "image/png"
 	"os"
 
+	"github.com/FinnStokes/huge/collision"
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/input"
+	"github.com/FinnStokes/huge/physics"
+	"github.com/FinnStokes/huge/render"
 	"github.com/FinnStokes/huge/sprite"
+	"github.com/FinnStokes/huge/system"
+	"github.com/FinnStokes/huge/tilemap"
 )
 
 // Manager is a type that stores the loaded resources and allows access with automatic loading.
 type Manager struct {
-	music   map[string]*Sound
-	sounds  map[string]*Sound
-	images  map[string]image.Image
-	json    map[string]*os.File
-	sprites map[string]*spriteSpec
+	music      map[string]*Sound
+	sounds     map[string]*Sound
+	images     map[string]image.Image
+	json       map[string]json.RawMessage
+	sprites    map[string]*spriteSpec
+	atlas      *render.Atlas
+	frames     map[frameKey][]render.Region
+	prefabs    map[string]*entity.Prefab
+	scenes     map[string]*sceneSpec
+	tilemaps   map[string]*tilemap.Map
+	bindings   map[string]input.Bindings
+	systems    map[string]func() system.System
+	components []func(entities *entity.Manager)
 }
 
 // NewManager returns an initialised resource manager.
@@ -25,8 +40,19 @@ func NewManager() *Manager {
 	m.music = make(map[string]*Sound)
 	m.sounds = make(map[string]*Sound)
 	m.images = make(map[string]image.Image)
-	m.json = make(map[string]*os.File)
+	m.json = make(map[string]json.RawMessage)
 	m.sprites = make(map[string]*spriteSpec)
+	m.atlas = render.NewAtlas(1024, 1024)
+	m.frames = make(map[frameKey][]render.Region)
+	m.prefabs = make(map[string]*entity.Prefab)
+	m.scenes = make(map[string]*sceneSpec)
+	m.tilemaps = make(map[string]*tilemap.Map)
+	m.bindings = make(map[string]input.Bindings)
+	m.systems = make(map[string]func() system.System)
+	m.RegisterSystem("sprite", func() system.System { return sprite.NewManager() })
+	m.RegisterSystem("tilemap", func() system.System { return tilemap.NewManager() })
+	m.RegisterSystem("collision", func() system.System { return collision.NewManager() })
+	m.RegisterSystem("physics", func() system.System { return physics.NewManager() })
 	return m
 }
 
@@ -38,6 +64,7 @@ func (m *Manager) GetSound(name string) (s *Sound, err error) {
 		if err != nil {
 			return nil, err
 		}
+		s.name = name
 		m.sounds[name] = s
 	}
 	return s, nil
@@ -51,6 +78,8 @@ func (m *Manager) GetMusic(name string) (s *Sound, err error) {
 		if err != nil {
 			return nil, err
 		}
+		s.name = name
+		s.music = true
 		s.Looping = true
 		m.music[name] = s
 	}
@@ -74,34 +103,57 @@ func (m *Manager) GetImage(name string) (img image.Image, err error) {
 	return img, nil
 }
 
-// GetJson fetches a json file and loads it into the given struct
-func (m *Manager) GetJson(name string, target interface{}) (err error) {
-	file, ok := m.json[name]
+// SetAtlas sets the atlas that the frames of sprites are packed into as they are loaded, so that
+// sprites from different images can be drawn together. By default the frames are packed onto
+// 1024 by 1024 pages. If a is nil, each sprite is drawn from its own image.
+func (m *Manager) SetAtlas(a *render.Atlas) {
+	m.atlas = a
+	m.frames = make(map[frameKey][]render.Region)
+}
+
+// Atlas returns the atlas that sprite frames are packed into, or nil if packing is disabled.
+func (m *Manager) Atlas() *render.Atlas {
+	return m.atlas
+}
+
+// GetJson fetches a json file and loads it into the given struct. Files are only read once, and
+// are kept only if they contain valid json, so that a broken file can be fixed and fetched again
+func (m *Manager) GetJson(name string, target interface{}) error {
+	data, ok := m.json[name]
 	if !ok {
-		file, err = os.Open(name + ".json")
+		file, err := os.Open(name + ".json")
 		if err != nil {
 			return err
 		}
-		m.json[name] = file
-	}
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(target)
-	if err != nil {
-		return err
+		defer file.Close()
+		if err := json.NewDecoder(file).Decode(&data); err != nil {
+			return err
+		}
+		m.json[name] = data
 	}
-	return nil
+	return json.Unmarshal(data, target)
 }
 
-// GetSprite creates a sprite based on the specification in a .json file
+// GetSprite creates a sprite based on the specification in a .json file, which may also be a
+// sprite sheet exported as JSON by Aseprite or TexturePacker
 func (m *Manager) GetSprite(name string) (s *sprite.Sprite, err error) {
 	sprite, ok := m.sprites[name]
 	if !ok {
-		sprite = new(spriteSpec)
-		err = m.GetJson(name, sprite)
+		var data json.RawMessage
+		err = m.GetJson(name, &data)
+		if err != nil {
+			return nil, err
+		}
+		sprite, err = parseSprite(name, data)
 		if err != nil {
 			return nil, err
 		}
 		m.sprites[name] = sprite
 	}
-	return sprite.New(m)
+	s, err = sprite.New(m)
+	if err != nil {
+		return nil, err
+	}
+	s.Name = name
+	return s, nil
 }
@@ -0,0 +1,51 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/FinnStokes/huge/entity"
+)
+
+type prefabSpec struct {
+	Parent     string
+	Components map[string]json.RawMessage
+	Tags       []string
+	Groups     []string
+}
+
+// GetPrefab fetches an entity template from a .json file. The file may name a parent prefab,
+// which is fetched in turn and inherited from. Components are given in the form expected by
+// their codecs, so sprites and sounds are referred to by resource name, as in
+// {"sprite": {"Name": "player"}}.
+func (m *Manager) GetPrefab(name string) (*entity.Prefab, error) {
+	return m.getPrefab(name, make(map[string]bool))
+}
+
+func (m *Manager) getPrefab(name string, loading map[string]bool) (*entity.Prefab, error) {
+	if p, ok := m.prefabs[name]; ok {
+		return p, nil
+	}
+	if loading[name] {
+		return nil, fmt.Errorf("resource: prefab %q inherits from itself", name)
+	}
+	loading[name] = true
+	spec := new(prefabSpec)
+	if err := m.GetJson(name, spec); err != nil {
+		return nil, err
+	}
+	p := &entity.Prefab{
+		Components: spec.Components,
+		Tags:       spec.Tags,
+		Groups:     spec.Groups,
+	}
+	if spec.Parent != "" {
+		parent, err := m.getPrefab(spec.Parent, loading)
+		if err != nil {
+			return nil, err
+		}
+		p.Parent = parent
+	}
+	m.prefabs[name] = p
+	return p, nil
+}
@@ -0,0 +1,52 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/physics"
+)
+
+func TestGetPrefab(t *testing.T) {
+	m := NewManager()
+	entities := entity.NewManager()
+	m.RegisterComponents(entities)
+	p, err := m.GetPrefab("testdata/enemy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := m.GetPrefab("testdata/enemy"); again != p {
+		t.Errorf("Fetching a prefab again did not return the cached prefab")
+	}
+	e, err := entities.Spawn(p, map[string]interface{}{"pos": map[string]float32{"X": 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos, _ := entity.Positions(entities).Get(e)
+	body, _ := physics.Bodies(entities).Get(e)
+	v, _ := physics.Velocities(entities).Get(e)
+	if pos == nil || pos.X != 5 || pos.Y != 20 {
+		t.Errorf("Spawned at %v, expected 5, 20", pos)
+	}
+	if body == nil || body.Width != 16 || body.Height != 24 || body.Mass != 4 {
+		t.Errorf("Spawned with body %v, expected 16x24 with mass 4 from parent and prefab", body)
+	}
+	if v == nil || v.X != -30 {
+		t.Errorf("Spawned with velocity %v, expected -30", v)
+	}
+	if entities.Tag("boss") != e || !entities.InGroup(e, "creatures") || !entities.InGroup(e, "enemies") {
+		t.Errorf("Spawned without the tags and groups of the prefab and its parent")
+	}
+
+	if _, err := m.GetPrefab("testdata/loop"); err == nil {
+		t.Errorf("Expected error for prefab inheriting from itself")
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := m.GetPrefab("testdata/broken"); err == nil || err.Error() != "unexpected EOF" {
+			t.Errorf("Fetch %v of broken prefab gave error %v, expected unexpected EOF", i, err)
+		}
+	}
+	if _, err := m.GetPrefab("testdata/missing"); err == nil {
+		t.Errorf("Expected error for missing prefab")
+	}
+}
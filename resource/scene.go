@@ -0,0 +1,97 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/FinnStokes/huge/camera"
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/scene"
+	"github.com/FinnStokes/huge/system"
+)
+
+type sceneSpec struct {
+	Camera      camera.Rectangle
+	Scale       camera.ScaleMode
+	Transparent bool
+	Systems     []systemSpec
+	Tilemaps    []string
+	Entities    []sceneEntitySpec
+}
+
+type systemSpec struct {
+	Name  string
+	Speed system.Speed
+}
+
+// sceneEntitySpec describes an entity in a scene, optionally based on a named prefab.
+type sceneEntitySpec struct {
+	Prefab     string
+	Components map[string]json.RawMessage
+	Tags       []string
+	Groups     []string
+}
+
+// RegisterSystem makes a system available to scene files under the given name. Each scene using
+// the system calls f to create its own instance.
+func (m *Manager) RegisterSystem(name string, f func() system.System) {
+	m.systems[name] = f
+}
+
+// GetScene creates a new scene from a level described in a .json file, giving the camera's
+// in-world rectangle and how it is scaled to fit the window, the systems to add by registered
+// name and speed, the tile maps to spawn along with their objects, and the entities to spawn, each
+// of which may be based on a prefab:
+//
+//	{
+//	    "camera": {"x": 0, "y": 0, "width": 640, "height": 480},
+//	    "scale": "letterbox",
+//	    "systems": [{"name": "tilemap", "speed": "normal"}, {"name": "sprite", "speed": "normal"}],
+//	    "tilemaps": ["level1"],
+//	    "entities": [{"prefab": "player", "components": {"pos": {"x": 32, "y": 32}}}]
+//	}
+func (m *Manager) GetScene(name string) (*scene.Scene, error) {
+	spec, ok := m.scenes[name]
+	if !ok {
+		spec = new(sceneSpec)
+		if err := m.GetJson(name, spec); err != nil {
+			return nil, err
+		}
+		m.scenes[name] = spec
+	}
+	s := scene.New()
+	s.Camera.World = spec.Camera
+	s.Camera.Mode = spec.Scale
+	s.Transparent = spec.Transparent
+	m.RegisterComponents(s.Entities)
+	for _, sys := range spec.Systems {
+		f, ok := m.systems[sys.Name]
+		if !ok {
+			return nil, fmt.Errorf("resource: scene %q uses unknown system %q", name, sys.Name)
+		}
+		s.Systems.AddSystem(sys.Speed, f())
+	}
+	for _, tm := range spec.Tilemaps {
+		if _, err := m.SpawnTilemap(s.Entities, tm); err != nil {
+			return nil, fmt.Errorf("resource: scene %q: %v", name, err)
+		}
+	}
+	for _, e := range spec.Entities {
+		prefab := &entity.Prefab{
+			Components: e.Components,
+			Tags:       e.Tags,
+			Groups:     e.Groups,
+		}
+		if e.Prefab != "" {
+			parent, err := m.GetPrefab(e.Prefab)
+			if err != nil {
+				return nil, err
+			}
+			prefab.Parent = parent
+		}
+		if _, err := s.Entities.Spawn(prefab, nil); err != nil {
+			return nil, fmt.Errorf("resource: scene %q: %v", name, err)
+		}
+	}
+	return s, nil
+}
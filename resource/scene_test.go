@@ -0,0 +1,54 @@
+package resource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FinnStokes/huge/camera"
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/system"
+)
+
+func TestGetScene(t *testing.T) {
+	m := NewManager()
+	s, err := m.GetScene("testdata/level")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Camera.World != (camera.Rectangle{Width: 320, Height: 240}) || !s.Transparent {
+		t.Errorf("Loaded camera %v, transparent %v, expected 320x240 and transparent", s.Camera.World, s.Transparent)
+	}
+	positions := entity.Positions(s.Entities)
+	enemy := s.Entities.Tag("boss")
+	exit := s.Entities.Tag("exit")
+	if enemy == nil || exit == nil || !s.Entities.InGroup(enemy, "enemies") || !s.Entities.InGroup(exit, "doors") {
+		t.Fatalf("Loaded scene is missing its entities, tags or groups")
+	}
+	if pos, _ := positions.Get(enemy); pos.X != 100 || pos.Y != 20 {
+		t.Errorf("Enemy at %v, expected position from prefab overridden to 100, 20", pos)
+	}
+	if pos, _ := positions.Get(exit); pos.X != 50 || pos.Y != 60 {
+		t.Errorf("Exit at %v, expected 50, 60", pos)
+	}
+
+	// The physics system only runs at fast speed.
+	s.Update(system.Slow, time.Second)
+	if pos, _ := positions.Get(enemy); pos.X != 100 {
+		t.Errorf("Enemy moved to %v at slow speed, expected physics to run only at fast speed", pos.X)
+	}
+	s.Update(system.Fast, 100*time.Millisecond)
+	if pos, _ := positions.Get(enemy); pos.X >= 100 {
+		t.Errorf("Enemy did not move at fast speed")
+	}
+
+	other, err := m.GetScene("testdata/level")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Entities == s.Entities || other.Entities.Tag("boss") == enemy {
+		t.Errorf("Loading a scene again did not create new entities")
+	}
+	if _, err := m.GetScene("testdata/badlevel"); err == nil {
+		t.Errorf("Expected error for scene with unknown system")
+	}
+}
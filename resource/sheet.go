@@ -0,0 +1,228 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// sheetFps is the frame rate given to animations imported from sheets without frame durations.
+const sheetFps = 10
+
+// sheetSpec is a sprite sheet exported as JSON by Aseprite or TexturePacker. The frames may be
+// either a hash keyed by file name or an array, and animations come from Aseprite's frame tags,
+// TexturePacker's animation lists or, failing those, the frame names.
+type sheetSpec struct {
+	Frames     json.RawMessage
+	Animations map[string][]string
+	Meta       struct {
+		Image     string
+		FrameTags []struct {
+			Name      string
+			From, To  int
+			Direction string
+		}
+	}
+}
+
+type sheetFrame struct {
+	Filename         string
+	Frame            sheetRect
+	Rotated          bool
+	Trimmed          bool
+	SpriteSourceSize sheetRect
+	SourceSize       struct{ W, H int }
+	Duration         int
+}
+
+type sheetRect struct {
+	X, Y, W, H int
+}
+
+// parseSprite reads a sprite from JSON, which may be either our own format or a sheet exported
+// from another tool, recognised by its "meta" section. The name of the sprite is used to find a
+// sheet's image, which is given relative to the sheet.
+func parseSprite(name string, data []byte) (*spriteSpec, error) {
+	var probe struct {
+		Meta json.RawMessage
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+	if len(probe.Meta) == 0 {
+		s := new(spriteSpec)
+		if err := json.Unmarshal(data, s); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	var sheet sheetSpec
+	if err := json.Unmarshal(data, &sheet); err != nil {
+		return nil, err
+	}
+	return sheet.spec(name)
+}
+
+// spec converts the sheet into a sprite specification.
+func (sheet *sheetSpec) spec(name string) (*spriteSpec, error) {
+	if path.Ext(sheet.Meta.Image) != ".png" {
+		return nil, fmt.Errorf("resource: sprite sheet %q has unsupported image %q", name, sheet.Meta.Image)
+	}
+	frames, err := decodeFrames(sheet.Frames)
+	if err != nil {
+		return nil, err
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("resource: sprite sheet %q has no frames", name)
+	}
+	s := &spriteSpec{
+		Image:      path.Join(path.Dir(name), strings.TrimSuffix(sheet.Meta.Image, ".png")),
+		Animations: make(map[string]animationSpec),
+		regions:    make([]image.Rectangle, len(frames)),
+		offsets:    make([]image.Point, len(frames)),
+	}
+	index := make(map[string]int, len(frames))
+	for i, f := range frames {
+		if f.Rotated {
+			return nil, fmt.Errorf("resource: sprite sheet %q has rotated frame %q, which is not supported", name, f.Filename)
+		}
+		index[f.Filename] = i
+		s.regions[i] = image.Rect(f.Frame.X, f.Frame.Y, f.Frame.X+f.Frame.W, f.Frame.Y+f.Frame.H)
+		w, h := f.SourceSize.W, f.SourceSize.H
+		if f.Trimmed {
+			s.offsets[i] = image.Pt(f.SpriteSourceSize.X, f.SpriteSourceSize.Y)
+		} else {
+			w, h = f.Frame.W, f.Frame.H
+		}
+		if w > s.Width {
+			s.Width = w
+		}
+		if h > s.Height {
+			s.Height = h
+		}
+	}
+
+	var order []string
+	add := func(animation string, positions []int) {
+		a := animationSpec{Frames: positions, Next: animation, Fps: sheetFps}
+		for _, p := range positions {
+			if frames[p].Duration <= 0 {
+				a.Durations = nil
+				break
+			}
+			a.Durations = append(a.Durations, frames[p].Duration)
+		}
+		if _, ok := s.Animations[animation]; !ok {
+			order = append(order, animation)
+		}
+		s.Animations[animation] = a
+	}
+	switch {
+	case len(sheet.Meta.FrameTags) > 0:
+		for _, tag := range sheet.Meta.FrameTags {
+			if tag.From < 0 || tag.To >= len(frames) || tag.From > tag.To {
+				return nil, fmt.Errorf("resource: sprite sheet %q has tag %q outside its frames", name, tag.Name)
+			}
+			var positions []int
+			for i := tag.From; i <= tag.To; i++ {
+				positions = append(positions, i)
+			}
+			switch tag.Direction {
+			case "reverse":
+				reverse(positions)
+			case "pingpong":
+				for i := tag.To - 1; i > tag.From; i-- {
+					positions = append(positions, i)
+				}
+			case "pingpong_reverse":
+				reverse(positions)
+				for i := tag.From + 1; i < tag.To; i++ {
+					positions = append(positions, i)
+				}
+			}
+			add(tag.Name, positions)
+		}
+	case len(sheet.Animations) > 0:
+		names := make([]string, 0, len(sheet.Animations))
+		for animation := range sheet.Animations {
+			names = append(names, animation)
+		}
+		sort.Strings(names)
+		for _, animation := range names {
+			var positions []int
+			for _, frame := range sheet.Animations[animation] {
+				i, ok := index[frame]
+				if !ok {
+					return nil, fmt.Errorf("resource: sprite sheet %q animation %q has no frame %q", name, animation, frame)
+				}
+				positions = append(positions, i)
+			}
+			add(animation, positions)
+		}
+	default:
+		groups := make(map[string][]int)
+		var names []string
+		for i, f := range frames {
+			animation := frameGroup(f.Filename)
+			if _, ok := groups[animation]; !ok {
+				names = append(names, animation)
+			}
+			groups[animation] = append(groups[animation], i)
+		}
+		for _, animation := range names {
+			add(animation, groups[animation])
+		}
+	}
+	s.Playing = order[0]
+	return s, nil
+}
+
+// decodeFrames reads the frames of a sheet from either an array or a hash, keeping the order
+// they appear in the file.
+func decodeFrames(data json.RawMessage) ([]sheetFrame, error) {
+	var frames []sheetFrame
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		err := json.Unmarshal(data, &frames)
+		return frames, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, fmt.Errorf("resource: sprite sheet frames are not an array or object")
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var f sheetFrame
+		if err := dec.Decode(&f); err != nil {
+			return nil, err
+		}
+		f.Filename = t.(string)
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+// frameGroup returns the name of the animation that a frame belongs to, taken from its file name
+// without the extension or frame number, so that "walk_01.png" and "walk_02.png" both belong to
+// "walk".
+func frameGroup(filename string) string {
+	name := strings.TrimSuffix(filename, path.Ext(filename))
+	trimmed := strings.TrimRightFunc(name, unicode.IsDigit)
+	if trimmed == name {
+		return name
+	}
+	return strings.TrimRight(trimmed, " _-.")
+}
+
+func reverse(s []int) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
@@ -0,0 +1,76 @@
+package resource
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestAseprite(t *testing.T) {
+	s, err := parseSprite("art/hero", []byte(`{
+		"frames": {
+			"hero 0.aseprite": {"frame": {"x": 0, "y": 0, "w": 16, "h": 24}, "rotated": false, "trimmed": false,
+				"spriteSourceSize": {"x": 0, "y": 0, "w": 16, "h": 24}, "sourceSize": {"w": 16, "h": 24}, "duration": 100},
+			"hero 1.aseprite": {"frame": {"x": 16, "y": 0, "w": 12, "h": 20}, "rotated": false, "trimmed": true,
+				"spriteSourceSize": {"x": 2, "y": 4, "w": 12, "h": 20}, "sourceSize": {"w": 16, "h": 24}, "duration": 150},
+			"hero 2.aseprite": {"frame": {"x": 28, "y": 0, "w": 16, "h": 24}, "rotated": false, "trimmed": false,
+				"spriteSourceSize": {"x": 0, "y": 0, "w": 16, "h": 24}, "sourceSize": {"w": 16, "h": 24}, "duration": 200}
+		},
+		"meta": {
+			"app": "http://www.aseprite.org/",
+			"image": "hero.png",
+			"frameTags": [
+				{"name": "walk", "from": 0, "to": 2, "direction": "pingpong"},
+				{"name": "fall", "from": 1, "to": 2, "direction": "reverse"}
+			]
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Image != "art/hero" || s.Width != 16 || s.Height != 24 || s.Playing != "walk" {
+		t.Errorf("Imported %v %vx%v playing %v, expected art/hero 16x24 playing walk", s.Image, s.Width, s.Height, s.Playing)
+	}
+	if r := s.regions[1]; r != image.Rect(16, 0, 28, 20) || s.offsets[1] != image.Pt(2, 4) {
+		t.Errorf("Trimmed frame in %v offset by %v, expected (16,0)-(28,20) offset by (2,4)", r, s.offsets[1])
+	}
+	expected := map[string]animationSpec{
+		"walk": {Frames: []int{0, 1, 2, 1}, Durations: []int{100, 150, 200, 150}, Fps: sheetFps, Next: "walk"},
+		"fall": {Frames: []int{2, 1}, Durations: []int{200, 150}, Fps: sheetFps, Next: "fall"},
+	}
+	if !reflect.DeepEqual(s.Animations, expected) {
+		t.Errorf("Imported animations %+v, expected %+v", s.Animations, expected)
+	}
+}
+
+func TestTexturePacker(t *testing.T) {
+	frames := `"frames": [
+		{"filename": "run_01.png", "frame": {"x": 0, "y": 0, "w": 8, "h": 8}, "sourceSize": {"w": 8, "h": 8}},
+		{"filename": "run_02.png", "frame": {"x": 8, "y": 0, "w": 8, "h": 8}, "sourceSize": {"w": 8, "h": 8}},
+		{"filename": "idle.png", "frame": {"x": 0, "y": 8, "w": 10, "h": 6}, "sourceSize": {"w": 10, "h": 6}}
+	],
+	"meta": {"app": "https://www.codeandweb.com/texturepacker", "image": "sheet.png"}`
+	s, err := parseSprite("sheet", []byte(`{`+frames+`}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]animationSpec{
+		"run":  {Frames: []int{0, 1}, Fps: sheetFps, Next: "run"},
+		"idle": {Frames: []int{2}, Fps: sheetFps, Next: "idle"},
+	}
+	if !reflect.DeepEqual(s.Animations, expected) || s.Playing != "run" || s.Width != 10 || s.Height != 8 {
+		t.Errorf("Imported animations %+v playing %v at %vx%v, expected %+v playing run at 10x8", s.Animations, s.Playing, s.Width, s.Height, expected)
+	}
+
+	s, err = parseSprite("sheet", []byte(`{`+frames+`, "animations": {"dash": ["run_02.png", "run_01.png"]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := s.Animations["dash"]; len(s.Animations) != 1 || !reflect.DeepEqual(a.Frames, []int{1, 0}) {
+		t.Errorf("Imported animations %+v, expected dash from listed frames", s.Animations)
+	}
+
+	if _, err := parseSprite("sheet", []byte(`{`+frames+`, "animations": {"dash": ["missing.png"]}}`)); err == nil {
+		t.Error("Imported animation with missing frame, expected error")
+	}
+}
@@ -22,6 +22,8 @@ const (
 type Sound struct {
 	Looping bool
 	Volume  float32
+	name    string
+	music   bool
 	state   playState
 	file    *sndfile.File
 	stream  *portaudio.Stream
@@ -1,6 +1,16 @@
 package resource
 
-import "github.com/FinnStokes/huge/sprite"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/render"
+	"github.com/FinnStokes/huge/sprite"
+)
 
 type spriteSpec struct {
 	Image      string
@@ -8,21 +18,34 @@ type spriteSpec struct {
 	Height     int
 	Animations map[string]animationSpec
 	Playing    string
+	// regions and offsets give the frames of sprite sheets imported from other tools, which are
+	// not laid out in a grid.
+	regions []image.Rectangle
+	offsets []image.Point
 }
 
+// animationSpec describes an animation. Durations gives the time to show each frame in
+// milliseconds, and Events the events fired on reaching each position in Frames.
 type animationSpec struct {
-	Frames []int
-	Fps    int
-	Next   string
+	Frames    []int
+	Fps       int
+	Durations []int
+	Events    map[int][]string
+	Next      string
 }
 
 func (s *spriteSpec) New(m *Manager) (*sprite.Sprite, error) {
 	animations := make(map[string]*sprite.Animation, len(s.Animations))
 	for k, a := range s.Animations {
+		durations := make([]time.Duration, len(a.Durations))
+		for i, d := range a.Durations {
+			durations[i] = time.Duration(d) * time.Millisecond
+		}
 		animations[k] = &sprite.Animation{
-			a.Frames,
-			a.Fps,
-			nil,
+			Frames:    a.Frames,
+			Fps:       a.Fps,
+			Durations: durations,
+			Events:    a.Events,
 		}
 	}
 	for k, a := range s.Animations {
@@ -32,12 +55,129 @@ func (s *spriteSpec) New(m *Manager) (*sprite.Sprite, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &sprite.Sprite{
-		img,
-		animations,
-		s.Width, s.Height,
-		animations[s.Playing],
-		0,
-		0,
-	}, nil
+	sp := &sprite.Sprite{
+		Image:            img,
+		Animations:       animations,
+		Regions:          s.regions,
+		Offsets:          s.offsets,
+		Width:            s.Width,
+		Height:           s.Height,
+		CurrentAnimation: animations[s.Playing],
+	}
+	if regions := m.packFrames(s, img); regions != nil {
+		sp.Image = regions[0].Page
+		sp.Regions = make([]image.Rectangle, len(regions))
+		for i, r := range regions {
+			sp.Regions[i] = r.Bounds
+		}
+	}
+	return sp, nil
+}
+
+// frameKey identifies the frames cut from an image for a sprite. Sheets imported from other
+// tools have a fixed set of frames for their image, and are given a width and height of zero.
+type frameKey struct {
+	image         string
+	width, height int
+}
+
+// packFrames cuts the sprite's image into its frames and packs them onto a single page of the
+// atlas, returning their regions. Images are only packed once for each set of frames. It returns
+// nil if there is no atlas or the frames do not fit on a page, in which case the sprite is drawn
+// from the image itself.
+func (m *Manager) packFrames(s *spriteSpec, img image.Image) []render.Region {
+	key := frameKey{s.Image, s.Width, s.Height}
+	rects := s.regions
+	if rects != nil {
+		key.width, key.height = 0, 0
+	} else if s.Width > 0 && s.Height > 0 {
+		b := img.Bounds()
+		for y := b.Min.Y; y+s.Height <= b.Max.Y; y += s.Height {
+			for x := b.Min.X; x+s.Width <= b.Max.X; x += s.Width {
+				rects = append(rects, image.Rect(x, y, x+s.Width, y+s.Height))
+			}
+		}
+	}
+	if regions, ok := m.frames[key]; ok {
+		return regions
+	}
+	sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if m.atlas == nil || !ok || len(rects) == 0 {
+		return nil
+	}
+	frames := make([]image.Image, len(rects))
+	for i, r := range rects {
+		frames[i] = sub.SubImage(r)
+	}
+	regions, err := m.atlas.AddAll(frames)
+	if err != nil {
+		regions = nil
+	}
+	m.frames[key] = regions
+	return regions
+}
+
+// spriteState is the saved form of a sprite, referring to its resource by name.
+type spriteState struct {
+	Name      string
+	Animation string        `json:",omitempty"`
+	Frame     int           `json:",omitempty"`
+	Time      time.Duration `json:",omitempty"`
+	Stopped   bool          `json:",omitempty"`
+	Entered   bool          `json:",omitempty"`
+}
+
+type spriteCodec struct {
+	m *Manager
+}
+
+// SpriteCodec returns a codec that saves sprites by the name of the resource they were loaded
+// from along with their current animation and frame, and whether that frame's events have fired,
+// and loads them through the manager.
+func (m *Manager) SpriteCodec() entity.Codec[*sprite.Sprite] {
+	return spriteCodec{m}
+}
+
+func (c spriteCodec) Marshal(s *sprite.Sprite) ([]byte, error) {
+	if s.Name == "" {
+		return nil, errors.New("resource: cannot save sprite not loaded from a resource")
+	}
+	return json.Marshal(&spriteState{
+		Name:      s.Name,
+		Animation: s.AnimationName(),
+		Frame:     s.CurrentFrame,
+		Time:      s.FrameTime,
+		Stopped:   !s.Playing(),
+		Entered:   s.Entered,
+	})
+}
+
+func (c spriteCodec) Unmarshal(data []byte) (*sprite.Sprite, error) {
+	var state spriteState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	s, err := c.m.GetSprite(state.Name)
+	if err != nil {
+		return nil, err
+	}
+	if state.Animation != "" {
+		a, ok := s.Animations[state.Animation]
+		if !ok {
+			return nil, fmt.Errorf("resource: sprite %q has no animation %q", state.Name, state.Animation)
+		}
+		s.CurrentAnimation = a
+	}
+	if s.CurrentAnimation != nil && state.Frame >= len(s.CurrentAnimation.Frames) {
+		return nil, fmt.Errorf("resource: sprite %q has no frame %v", state.Name, state.Frame)
+	}
+	s.CurrentFrame = state.Frame
+	s.FrameTime = state.Time
+	s.Entered = state.Entered
+	if state.Stopped {
+		s.Stop()
+	}
+	return s, nil
 }
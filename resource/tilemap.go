@@ -0,0 +1,132 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/tilemap"
+)
+
+// GetTilemap fetches a map made with the Tiled editor, from a file in either its .json or .tmx
+// format, along with its external tilesets and the .png images of its tilesets.
+func (m *Manager) GetTilemap(name string) (*tilemap.Map, error) {
+	if tm, ok := m.tilemaps[name]; ok {
+		return tm, nil
+	}
+	open := func(p string) (io.ReadCloser, error) {
+		return os.Open(path.Join(path.Dir(name), p))
+	}
+	read := tilemap.ReadJSON
+	file, err := os.Open(name + ".json")
+	if os.IsNotExist(err) {
+		read = tilemap.ReadTMX
+		file, err = os.Open(name + ".tmx")
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	tm, err := read(file, open)
+	if err != nil {
+		return nil, fmt.Errorf("resource: tilemap %q: %v", name, err)
+	}
+	for _, ts := range tm.Tilesets {
+		if path.Ext(ts.ImagePath) != ".png" {
+			return nil, fmt.Errorf("resource: tilemap %q has unsupported tileset image %q", name, ts.ImagePath)
+		}
+		img, err := m.GetImage(path.Join(path.Dir(name), strings.TrimSuffix(ts.ImagePath, ".png")))
+		if err != nil {
+			return nil, err
+		}
+		ts.Image = img
+	}
+	tm.Name = name
+	m.tilemaps[name] = tm
+	return tm, nil
+}
+
+// SpawnTilemap creates an entity for the named map at the origin, along with an entity for each
+// object in its object layers. An object with a "prefab" property is spawned from the prefab it
+// names, while an object's type is left as a label for the game to interpret. Every object entity
+// is given a pos component at the object's top left corner, an object component, joins a group
+// named after its layer and is tagged with its name, if it has one. If any object cannot be
+// spawned, the entities created so far are deleted and an error is returned.
+func (m *Manager) SpawnTilemap(entities *entity.Manager, name string) (e *entity.Entity, err error) {
+	tm, err := m.GetTilemap(name)
+	if err != nil {
+		return nil, err
+	}
+	e = entities.New()
+	created := []*entity.Entity{e}
+	defer func() {
+		if err != nil {
+			for _, c := range created {
+				entities.Delete(c)
+			}
+			e = nil
+		}
+	}()
+	entity.Positions(entities).Set(e, &entity.Position{})
+	tilemap.Maps(entities).Set(e, tm)
+	for _, l := range tm.Layers {
+		for _, o := range l.Objects {
+			var obj *entity.Entity
+			if p := o.Properties.String("prefab"); p != "" {
+				prefab, err := m.GetPrefab(p)
+				if err != nil {
+					return nil, fmt.Errorf("resource: tilemap %q object %d: %v", name, o.Id, err)
+				}
+				if obj, err = entities.Spawn(prefab, nil); err != nil {
+					return nil, fmt.Errorf("resource: tilemap %q object %d: %v", name, o.Id, err)
+				}
+			} else {
+				obj = entities.New()
+			}
+			created = append(created, obj)
+			x, y := o.X+l.OffsetX, o.Y+l.OffsetY
+			if o.Tile != 0 {
+				// Tile objects are positioned by their bottom left corner.
+				y -= o.Height
+			}
+			entity.Positions(entities).Set(obj, &entity.Position{X: x, Y: y})
+			tilemap.Objects(entities).Set(obj, o)
+			if l.Name != "" {
+				entities.SetGroup(obj, l.Name)
+			}
+			if o.Name != "" {
+				entities.SetTag(obj, o.Name)
+			}
+		}
+	}
+	return e, nil
+}
+
+type tilemapCodec struct {
+	m *Manager
+}
+
+// TilemapCodec returns a codec that saves maps by the name of the resource they were loaded
+// from, and loads them through the manager.
+func (m *Manager) TilemapCodec() entity.Codec[*tilemap.Map] {
+	return tilemapCodec{m}
+}
+
+func (c tilemapCodec) Marshal(tm *tilemap.Map) ([]byte, error) {
+	if tm.Name == "" {
+		return nil, fmt.Errorf("resource: cannot save tilemap not loaded from a resource")
+	}
+	return json.Marshal(tm.Name)
+}
+
+func (c tilemapCodec) Unmarshal(data []byte) (*tilemap.Map, error) {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return nil, err
+	}
+	return c.m.GetTilemap(name)
+}
@@ -0,0 +1,56 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/physics"
+	"github.com/FinnStokes/huge/tilemap"
+)
+
+func TestSpawnTilemap(t *testing.T) {
+	m := NewManager()
+	tm, err := m.GetTilemap("testdata/map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tm.Name != "testdata/map" || len(tm.Tilesets) != 1 || tm.Tilesets[0].Image == nil {
+		t.Errorf("Loaded map %q without its tileset image", tm.Name)
+	}
+
+	entities := entity.NewManager()
+	m.RegisterComponents(entities)
+	e, err := m.SpawnTilemap(entities, "testdata/map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := tilemap.Maps(entities).Get(e); got != tm {
+		t.Errorf("Map entity has map %v, expected the loaded map", got)
+	}
+	start, guard := entities.Tag("start"), entities.Tag("guard")
+	if start == nil || guard == nil || !entities.InGroup(start, "spawns") {
+		t.Fatalf("Spawned objects are missing their tags or groups")
+	}
+	// The player's type has no prefab, so it is only a label.
+	if pos, _ := entity.Positions(entities).Get(start); pos.X != 20 || pos.Y != 8 {
+		t.Errorf("Start at %v, expected 20, 8 including the layer offset", pos)
+	}
+	if obj, _ := tilemap.Objects(entities).Get(start); obj.Type != "player" {
+		t.Errorf("Start has object %+v, expected type player", obj)
+	}
+	if body, _ := physics.Bodies(entities).Get(guard); body == nil || body.Mass != 4 {
+		t.Errorf("Guard has body %v, expected one from its prefab", body)
+	}
+	if pos, _ := entity.Positions(entities).Get(guard); pos.X != 104 || pos.Y != 40 {
+		t.Errorf("Guard at %v, expected the object's position rather than the prefab's", pos)
+	}
+
+	entities = entity.NewManager()
+	m.RegisterComponents(entities)
+	if _, err := m.SpawnTilemap(entities, "testdata/badmap"); err == nil {
+		t.Errorf("Expected error for object with missing prefab")
+	}
+	if n := len(entities.All()); n != 0 {
+		t.Errorf("Failed spawn left %v entities behind", n)
+	}
+}
@@ -0,0 +1,128 @@
+// Package scene divides a game into separate scenes, such as menus and levels, and manages the
+// stack of scenes that are currently active.
+package scene
+
+import (
+	"sort"
+	"time"
+
+	"github.com/FinnStokes/huge/camera"
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/render"
+	"github.com/FinnStokes/huge/system"
+)
+
+// MainCamera is the name of a scene's Camera among its cameras.
+const MainCamera = "main"
+
+// A Scene is a self-contained part of a game with its own entities, systems and cameras.
+type Scene struct {
+	// Camera is the scene's main camera, which along with any cameras added with AddCamera draws
+	// the scene.
+	Camera   *camera.Camera
+	Entities *entity.Manager
+	Systems  *system.Manager
+	// Transparent scenes are drawn over the scene beneath them on the stack, as for a pause
+	// screen over a level, rather than hiding it.
+	Transparent   bool
+	cameras       map[string]*camera.Camera
+	names         []string
+	order         []*camera.Camera
+	width, height int
+}
+
+// New returns an empty scene.
+func New() *Scene {
+	s := new(Scene)
+	s.Camera = new(camera.Camera)
+	s.Entities = entity.NewManager()
+	s.Systems = system.NewManager()
+	s.cameras = make(map[string]*camera.Camera)
+	return s
+}
+
+// AddCamera adds a camera to the scene under the given name, replacing any camera already using
+// the name, such as for each player's view in split-screen or a fixed camera for the HUD. If the
+// scene is on a stack, the camera is fitted to the window.
+func (s *Scene) AddCamera(name string, c *camera.Camera) {
+	if name == MainCamera {
+		s.Camera = c
+	} else {
+		if _, ok := s.cameras[name]; !ok {
+			s.names = append(s.names, name)
+		}
+		s.cameras[name] = c
+	}
+	s.fit(c)
+}
+
+// RemoveCamera removes the named camera from the scene. The main camera cannot be removed.
+func (s *Scene) RemoveCamera(name string) {
+	if _, ok := s.cameras[name]; !ok {
+		return
+	}
+	delete(s.cameras, name)
+	for i, n := range s.names {
+		if n == name {
+			s.names = append(s.names[:i], s.names[i+1:]...)
+			break
+		}
+	}
+}
+
+// NamedCamera returns the camera with the given name, or nil if there is none.
+func (s *Scene) NamedCamera(name string) *camera.Camera {
+	if name == MainCamera {
+		return s.Camera
+	}
+	return s.cameras[name]
+}
+
+// Cameras returns the scene's cameras in the order they are drawn, from lowest to highest Order
+// and then in the order they were added, starting with the main camera. The slice is owned by
+// the scene and is invalidated by the next call.
+func (s *Scene) Cameras() []*camera.Camera {
+	s.order = append(s.order[:0], s.Camera)
+	for _, name := range s.names {
+		s.order = append(s.order, s.cameras[name])
+	}
+	sort.SliceStable(s.order, func(i, j int) bool { return s.order[i].Order < s.order[j].Order })
+	return s.order
+}
+
+// Update updates the scene's systems at the specified speed.
+func (s *Scene) Update(speed system.Speed, dt time.Duration) {
+	s.Systems.Update(speed, dt, s.Entities)
+}
+
+// Draw draws the scene's systems through each of its cameras in turn, limiting drawing to the
+// camera's part of the window.
+func (s *Scene) Draw(r render.Renderer) {
+	for _, c := range s.Cameras() {
+		r.SetViewport(c.Screen.X, c.Screen.Y, c.Screen.Width, c.Screen.Height)
+		s.Systems.Draw(r, c, s.Entities)
+	}
+	r.SetViewport(0, 0, 0, 0)
+}
+
+// Close releases the resources that the scene's systems hold for its entities, such as textures.
+// The stack closes scenes once they have been removed from it and are no longer drawn, but a
+// closed scene may still be used again.
+func (s *Scene) Close() {
+	s.Systems.Close(s.Entities)
+}
+
+// resize fits the scene's cameras to a window of the given size.
+func (s *Scene) resize(width, height int) {
+	s.width, s.height = width, height
+	for _, c := range s.Cameras() {
+		s.fit(c)
+	}
+}
+
+func (s *Scene) fit(c *camera.Camera) {
+	if s.width == 0 || s.height == 0 {
+		return
+	}
+	c.Resize(s.width, s.height)
+}
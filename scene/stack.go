@@ -0,0 +1,193 @@
+package scene
+
+import (
+	"time"
+
+	"github.com/FinnStokes/huge/render"
+	"github.com/FinnStokes/huge/system"
+)
+
+// A Stack holds the active scenes of a game. Only the scene on top of the stack is updated, while
+// it and any scenes visible beneath it are drawn. Changes to the stack may be accompanied by a
+// transition, which controls how the change is drawn.
+type Stack struct {
+	scenes        []*Scene
+	transition    Transition
+	from          []*Scene
+	leaving       []*Scene
+	elapsed       time.Duration
+	width, height int
+}
+
+// NewStack returns an empty scene stack.
+func NewStack() *Stack {
+	return new(Stack)
+}
+
+// Top returns the scene on top of the stack, or nil if the stack is empty.
+func (s *Stack) Top() *Scene {
+	if len(s.scenes) == 0 {
+		return nil
+	}
+	return s.scenes[len(s.scenes)-1]
+}
+
+// Len returns the number of scenes on the stack.
+func (s *Stack) Len() int {
+	return len(s.scenes)
+}
+
+// Push places a scene on top of the stack using the given transition, which may be nil to
+// change scene immediately.
+func (s *Stack) Push(scene *Scene, t Transition) {
+	from := s.visible()
+	s.attach(scene)
+	s.scenes = append(s.scenes, scene)
+	s.begin(from, t, nil)
+}
+
+// Pop removes and returns the scene on top of the stack using the given transition, which may
+// be nil to change scene immediately. The scene is closed once the transition has finished.
+func (s *Stack) Pop(t Transition) *Scene {
+	top := s.Top()
+	if top == nil {
+		return nil
+	}
+	from := s.visible()
+	s.scenes[len(s.scenes)-1] = nil
+	s.scenes = s.scenes[:len(s.scenes)-1]
+	s.begin(from, t, top)
+	return top
+}
+
+// Replace swaps the scene on top of the stack for another using the given transition, which may
+// be nil to change scene immediately, and returns the replaced scene. The replaced scene is closed
+// once the transition has finished.
+func (s *Stack) Replace(scene *Scene, t Transition) *Scene {
+	from := s.visible()
+	var top *Scene
+	if len(s.scenes) > 0 {
+		top = s.scenes[len(s.scenes)-1]
+		s.scenes = s.scenes[:len(s.scenes)-1]
+	}
+	s.attach(scene)
+	s.scenes = append(s.scenes, scene)
+	s.begin(from, t, top)
+	return top
+}
+
+// Transitioning returns true if a transition is in progress.
+func (s *Stack) Transitioning() bool {
+	return s.transition != nil
+}
+
+// Update updates the systems of the scene on top of the stack at the specified speed.
+func (s *Stack) Update(speed system.Speed, dt time.Duration) {
+	if top := s.Top(); top != nil {
+		top.Update(speed, dt)
+	}
+}
+
+// Advance moves any transition in progress on by dt.
+func (s *Stack) Advance(dt time.Duration) {
+	if s.transition == nil {
+		return
+	}
+	s.elapsed += dt
+	if s.elapsed >= s.transition.Duration() {
+		s.transition = nil
+		s.from = nil
+		s.close()
+	}
+}
+
+// Draw draws the visible scenes, from the bottom of the stack up, using the current transition
+// if there is one.
+func (s *Stack) Draw(r render.Renderer) {
+	to := s.visible()
+	if s.transition == nil {
+		draw(r, to)
+		return
+	}
+	progress := float32(s.elapsed) / float32(s.transition.Duration())
+	s.transition.Draw(r, s.width, s.height, func() { draw(r, s.from) }, func() { draw(r, to) }, progress)
+}
+
+// Resize fits the cameras of all scenes on the stack, along with any pushed later, to a window of
+// the given size according to their scale modes.
+func (s *Stack) Resize(width, height int) {
+	s.width, s.height = width, height
+	for _, scene := range s.scenes {
+		s.attach(scene)
+	}
+	for _, scene := range s.from {
+		s.attach(scene)
+	}
+}
+
+// attach fits the cameras of a scene to the window.
+func (s *Stack) attach(scene *Scene) {
+	if s.width == 0 || s.height == 0 {
+		return
+	}
+	scene.resize(s.width, s.height)
+}
+
+// begin starts a transition away from the given visible scenes, after which the removed scene,
+// if not nil, is closed.
+func (s *Stack) begin(from []*Scene, t Transition, removed *Scene) {
+	// Scenes left by an unfinished transition are no longer drawn once it is interrupted.
+	s.close()
+	if removed != nil {
+		s.leaving = append(s.leaving, removed)
+	}
+	if t == nil || t.Duration() <= 0 {
+		s.transition = nil
+		s.from = nil
+		s.close()
+		return
+	}
+	s.transition = t
+	s.from = from
+	s.elapsed = 0
+}
+
+// close closes the scenes that have left the stack, unless they have since been put back on it.
+func (s *Stack) close() {
+	for _, scene := range s.leaving {
+		if !s.contains(scene) {
+			scene.Close()
+		}
+	}
+	s.leaving = nil
+}
+
+// contains returns true if the scene is on the stack.
+func (s *Stack) contains(scene *Scene) bool {
+	for _, sc := range s.scenes {
+		if sc == scene {
+			return true
+		}
+	}
+	return false
+}
+
+// visible returns the scenes that can currently be seen, from the bottom up.
+func (s *Stack) visible() []*Scene {
+	i := len(s.scenes) - 1
+	for i > 0 && s.scenes[i].Transparent {
+		i--
+	}
+	if i < 0 {
+		return nil
+	}
+	v := make([]*Scene, len(s.scenes)-i)
+	copy(v, s.scenes[i:])
+	return v
+}
+
+func draw(r render.Renderer, scenes []*Scene) {
+	for _, scene := range scenes {
+		scene.Draw(r)
+	}
+}
@@ -0,0 +1,210 @@
+package scene
+
+import (
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/FinnStokes/huge/camera"
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/render"
+	"github.com/FinnStokes/huge/system"
+)
+
+// painter is a system that fills part of the screen with a colour and counts its updates.
+type painter struct {
+	x       float32
+	c       color.RGBA
+	updates int
+}
+
+func (p *painter) Update(dt time.Duration, entities *entity.Manager) {
+	p.updates++
+}
+
+func (p *painter) Draw(r render.Renderer, c *camera.Camera, entities *entity.Manager) {
+	r.FillRect(p.x, 0, 10, 10, float32(p.c.R)/255, float32(p.c.G)/255, float32(p.c.B)/255, 1)
+}
+
+func newPainted(x float32, c color.RGBA) (*Scene, *painter) {
+	s := New()
+	p := &painter{x: x, c: c}
+	s.Systems.AddSystem(system.Normal, p)
+	return s, p
+}
+
+// frame draws the stack and returns the colours at the left and right of the screen.
+func frame(h *render.Headless, s *Stack) (left, right color.RGBA) {
+	r := h.Renderer()
+	r.Clear(1, 1, 1, 1)
+	s.Draw(r)
+	h.SwapBuffers()
+	return h.Image().RGBAAt(5, 5), h.Image().RGBAAt(15, 5)
+}
+
+func TestStack(t *testing.T) {
+	h := render.NewHeadless(20, 10)
+	h.Open(20, 10, "test")
+	white := color.RGBA{255, 255, 255, 255}
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+
+	s := NewStack()
+	s.Resize(20, 10)
+	a, pa := newPainted(0, red)
+	b, pb := newPainted(0, blue)
+	c, pc := newPainted(10, blue)
+	c.Transparent = true
+
+	s.Push(a, nil)
+	if l, _ := frame(h, s); l != red {
+		t.Errorf("First scene drew %v, expected red", l)
+	}
+	if a.Camera.Screen.Width != 20 || a.Camera.World.Height != 10 {
+		t.Errorf("Pushed scene camera not sized to screen: %v", a.Camera)
+	}
+
+	s.Push(b, Fade(100*time.Millisecond, 0, 0, 0))
+	s.Update(system.Normal, time.Millisecond)
+	if pa.updates != 0 || pb.updates != 1 {
+		t.Errorf("Updated scenes other than the top")
+	}
+	s.Advance(25 * time.Millisecond)
+	if l, _ := frame(h, s); l != (color.RGBA{127, 0, 0, 255}) {
+		t.Errorf("Fading out drew %v, expected dark red", l)
+	}
+	s.Advance(50 * time.Millisecond)
+	if l, _ := frame(h, s); l != (color.RGBA{0, 0, 127, 255}) {
+		t.Errorf("Fading in drew %v, expected dark blue", l)
+	}
+	s.Advance(25 * time.Millisecond)
+	if l, _ := frame(h, s); l != blue || s.Transitioning() {
+		t.Errorf("Finished transition drew %v, expected blue", l)
+	}
+
+	if old := s.Replace(c, nil); old != b {
+		t.Errorf("Replace returned wrong scene")
+	}
+	if l, r := frame(h, s); l != red || r != blue {
+		t.Errorf("Transparent scene drew %v and %v, expected red and blue", l, r)
+	}
+	s.Update(system.Normal, time.Millisecond)
+	if pa.updates != 0 || pc.updates != 1 {
+		t.Errorf("Updated scenes beneath transparent scene")
+	}
+
+	if s.Pop(nil) != c || s.Pop(nil) != a || s.Pop(nil) != nil || s.Len() != 0 {
+		t.Errorf("Popped scenes do not match")
+	}
+	if l, r := frame(h, s); l != white || r != white {
+		t.Errorf("Empty stack drew %v and %v", l, r)
+	}
+}
+
+// viewer is a system that fills the top left corner of each camera's screen with a colour chosen
+// by the camera's layer mask.
+type viewer struct {
+	colors map[uint32]color.RGBA
+}
+
+func (v *viewer) Update(dt time.Duration, entities *entity.Manager) {}
+
+func (v *viewer) Draw(r render.Renderer, c *camera.Camera, entities *entity.Manager) {
+	col := v.colors[c.Layers]
+	r.FillRect(0, 0, 4, 4, float32(col.R)/255, float32(col.G)/255, float32(col.B)/255, 1)
+}
+
+func TestCameras(t *testing.T) {
+	h := render.NewHeadless(20, 10)
+	h.Open(20, 10, "test")
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	green := color.RGBA{0, 255, 0, 255}
+
+	sc := New()
+	sc.Systems.AddSystem(system.Normal, &viewer{map[uint32]color.RGBA{1: red, 2: blue, 4: green}})
+	sc.Camera.Viewport = camera.Rectangle{Width: 0.5, Height: 1}
+	sc.Camera.Layers = 1
+	sc.AddCamera("right", &camera.Camera{Viewport: camera.Rectangle{X: 0.5, Width: 0.5, Height: 1}, Layers: 2})
+	s := NewStack()
+	s.Resize(20, 10)
+	s.Push(sc, nil)
+	hud := &camera.Camera{Order: 1, Layers: 4, World: camera.Rectangle{Width: 100, Height: 50}}
+	sc.AddCamera("hud", hud)
+
+	right := sc.NamedCamera("right")
+	if right.Screen != (camera.Screen{X: 10, Y: 0, Width: 10, Height: 10}) || right.World.Width != 10 {
+		t.Errorf("Right camera not fitted to its viewport: %v", right)
+	}
+	if hud.Screen.Width != 20 || hud.World.Width != 100 {
+		t.Errorf("HUD camera not fitted to the window: %v", hud)
+	}
+	cameras := sc.Cameras()
+	if len(cameras) != 3 || cameras[0] != sc.Camera || cameras[1] != right || cameras[2] != hud {
+		t.Errorf("Wrong camera order %v", cameras)
+	}
+
+	frame(h, s)
+	img := h.Image()
+	if c := img.RGBAAt(1, 1); c != green {
+		t.Errorf("HUD drew %v, expected green over the left camera", c)
+	}
+	if c := img.RGBAAt(11, 1); c != blue {
+		t.Errorf("Right camera drew %v, expected blue", c)
+	}
+	if c := img.RGBAAt(5, 5); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("Drew %v outside the squares", c)
+	}
+
+	sc.RemoveCamera("hud")
+	frame(h, s)
+	if c := h.Image().RGBAAt(1, 1); c != red {
+		t.Errorf("Left camera drew %v, expected red", c)
+	}
+}
+
+// closer is a system that counts how many times it is closed.
+type closer struct {
+	closed int
+}
+
+func (c *closer) Update(dt time.Duration, entities *entity.Manager) {}
+
+func (c *closer) Draw(r render.Renderer, cam *camera.Camera, entities *entity.Manager) {}
+
+func (c *closer) Close(entities *entity.Manager) {
+	c.closed++
+}
+
+func TestClose(t *testing.T) {
+	s := NewStack()
+	a, b := New(), New()
+	ca, cb := new(closer), new(closer)
+	a.Systems.AddSystem(system.Normal, ca)
+	b.Systems.AddSystem(system.Normal, cb)
+
+	s.Push(a, nil)
+	s.Push(b, nil)
+	s.Pop(Fade(100*time.Millisecond, 0, 0, 0))
+	if cb.closed != 0 {
+		t.Errorf("Scene closed while still drawn by a transition")
+	}
+	s.Advance(100 * time.Millisecond)
+	if cb.closed != 1 || ca.closed != 0 {
+		t.Errorf("Closed %v and %v times after transition, expected 0 and 1", ca.closed, cb.closed)
+	}
+
+	s.Push(b, nil)
+	s.Replace(a, nil)
+	if cb.closed != 2 || ca.closed != 0 {
+		t.Errorf("Closed %v and %v times after replacing, expected 0 and 2", ca.closed, cb.closed)
+	}
+	s.Pop(nil)
+	if ca.closed != 0 {
+		t.Errorf("Closed a scene that is still on the stack")
+	}
+	s.Pop(nil)
+	if ca.closed != 1 {
+		t.Errorf("Closed %v times after popping, expected 1", ca.closed)
+	}
+}
@@ -0,0 +1,42 @@
+package scene
+
+import (
+	"time"
+
+	"github.com/FinnStokes/huge/render"
+)
+
+// A Transition controls how a change of scene is drawn. Draw is called each frame during the
+// transition with its progress, from 0 to 1, and functions that draw the scenes visible before
+// and after the change into a screen of the given size.
+type Transition interface {
+	Duration() time.Duration
+	Draw(r render.Renderer, width, height int, from, to func(), progress float32)
+}
+
+type fade struct {
+	duration time.Duration
+	r, g, b  float32
+}
+
+// Fade returns a transition that fades the old scenes out to the given colour over the first
+// half of the duration, and fades the new scenes in over the second half.
+func Fade(duration time.Duration, r, g, b float32) Transition {
+	return &fade{duration, r, g, b}
+}
+
+func (f *fade) Duration() time.Duration {
+	return f.duration
+}
+
+func (f *fade) Draw(r render.Renderer, width, height int, from, to func(), progress float32) {
+	var alpha float32
+	if progress < 0.5 {
+		from()
+		alpha = progress * 2
+	} else {
+		to()
+		alpha = (1 - progress) * 2
+	}
+	r.FillRect(0, 0, float32(width), float32(height), f.r, f.g, f.b, alpha)
+}
@@ -0,0 +1,64 @@
+package sprite
+
+import "github.com/FinnStokes/huge/entity"
+
+// Depth places a sprite in a numbered layer, with Z ordering it within the layer. Layers are
+// drawn from lowest to highest, and sprites without a depth component are drawn in layer 0.
+type Depth struct {
+	Layer int
+	Z     float32
+}
+
+// Depths returns the entity manager's store of depth components, registered as "depth".
+func Depths(m *entity.Manager) *entity.ComponentStore[*Depth] {
+	return entity.Register[*Depth](m, "depth")
+}
+
+// SortMode chooses the order in which the sprites within a layer are drawn.
+type SortMode int
+
+const (
+	// SortZ draws sprites from lowest to highest Z. Sprites with the same Z are grouped by texture,
+	// so that they take one draw call for each texture, and are otherwise drawn in the order they
+	// were created.
+	SortZ SortMode = iota
+	// SortY draws sprites from the highest to the lowest bottom edge on screen, so that things
+	// further down are in front, as in top-down games. Ties are broken by Z.
+	SortY
+	// SortInsertion draws sprites in the order they were created, ignoring Z.
+	SortInsertion
+	// SortTexture draws all the sprites sharing a texture together, in no particular order, which
+	// takes the fewest draw calls.
+	SortTexture
+)
+
+// Layer holds the settings for drawing a layer of sprites.
+type Layer struct {
+	Sort SortMode
+	// ParallaxX and ParallaxY scale how far the layer moves as the camera moves. A factor of 1
+	// moves with the world and less than 1 moves slower as if far away. A factor of 0 is taken
+	// to be 1, so that layers only need to set the factors they change.
+	ParallaxX, ParallaxY float32
+	// Fixed layers stay in place on screen however the camera moves, as for a HUD, ignoring
+	// their parallax.
+	Fixed bool
+}
+
+// parallax returns the layer's parallax factors, with zero factors taken to be 1.
+func (l Layer) parallax() (x, y float32) {
+	if l.Fixed {
+		return 0, 0
+	}
+	x, y = l.ParallaxX, l.ParallaxY
+	if x == 0 {
+		x = 1
+	}
+	if y == 0 {
+		y = 1
+	}
+	return x, y
+}
+
+// DefaultLayer is the setting for layers that have not been set, sorted by Z and moving with the
+// world.
+var DefaultLayer = Layer{Sort: SortZ, ParallaxX: 1, ParallaxY: 1}
@@ -2,134 +2,417 @@ package sprite
 
 import (
 	"image"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/FinnStokes/huge/camera"
 	"github.com/FinnStokes/huge/entity"
-
-	"github.com/go-gl/gl"
+	"github.com/FinnStokes/huge/render"
 )
 
-// Manager is a placeholder system that draws sprites for all entities with a position component
+// Manager is a system that animates and draws the sprites of all entities with sprite and
+// position components. Sprites are drawn in layers, culled against the camera's view and batched
+// by texture, and animation events are passed on to observers. Textures are uploaded as needed
+// and released once no sprite uses them.
 type Manager struct {
-	textures map[image.Image]gl.Texture
+	textures map[image.Image]render.Texture
+	versions map[image.Image]int
+	ranks    map[render.Texture]int
+	uploads  int
+	users    map[image.Image]int
+	watched  map[*entity.Manager]*watcher
+	batch    *render.Batch
+	layers   map[int]Layer
+	visible  []visible
+	views    map[int]*view
+	events   map[string][]func(e *entity.Entity)
+	finished []func(e *entity.Entity, animation string)
+	fired    []fired
 }
 
 // NewManager returns an initialised sprite manager
 func NewManager() *Manager {
 	m := new(Manager)
-	m.textures = make(map[image.Image]gl.Texture)
+	m.textures = make(map[image.Image]render.Texture)
+	m.versions = make(map[image.Image]int)
+	m.ranks = make(map[render.Texture]int)
+	m.users = make(map[image.Image]int)
+	m.watched = make(map[*entity.Manager]*watcher)
+	m.batch = render.NewBatch()
+	m.layers = make(map[int]Layer)
+	m.views = make(map[int]*view)
+	m.events = make(map[string][]func(e *entity.Entity))
 	return m
 }
 
+// watcher counts the sprites of an entity manager until it is closed.
+type watcher struct {
+	cancel []func()
+}
+
+// watch starts counting the sprites in entities that use each image, so that textures can be
+// released once no sprites use them.
+func (m *Manager) watch(entities *entity.Manager) {
+	if m.watched[entities] != nil {
+		return
+	}
+	w := new(watcher)
+	m.watched[entities] = w
+	sprites := Sprites(entities)
+	for _, sprite := range sprites.Components() {
+		m.users[sprite.Image]++
+	}
+	w.cancel = append(w.cancel,
+		entities.OnComponentAdded("sprite", func(e *entity.Entity) {
+			if sprite, ok := sprites.Get(e); ok {
+				m.users[sprite.Image]++
+			}
+		}),
+		entities.OnComponentRemoved("sprite", func(e *entity.Entity) {
+			if sprite, ok := sprites.Get(e); ok {
+				m.release(sprite.Image)
+			}
+		}),
+	)
+}
+
+// Close stops counting the sprites in entities, releasing the textures that only they use and
+// any references to the entities, such as when their scene is removed from the scene stack. The
+// entities are counted again if they are updated or drawn later.
+func (m *Manager) Close(entities *entity.Manager) {
+	w := m.watched[entities]
+	if w == nil {
+		return
+	}
+	for _, cancel := range w.cancel {
+		cancel()
+	}
+	delete(m.watched, entities)
+	for _, sprite := range Sprites(entities).Components() {
+		m.release(sprite.Image)
+	}
+	m.visible = nil
+	m.fired = nil
+}
+
+// release records that a sprite no longer uses img, releasing its texture if it was the last.
+func (m *Manager) release(img image.Image) {
+	m.users[img]--
+	if m.users[img] > 0 {
+		return
+	}
+	delete(m.users, img)
+	if tex, ok := m.textures[img]; ok {
+		tex.Release()
+		m.batch.Forget(tex)
+		delete(m.ranks, tex)
+		delete(m.textures, img)
+		delete(m.versions, img)
+	}
+}
+
+// versioned is implemented by images that can change after they are loaded, such as atlas pages.
+type versioned interface {
+	Version() int
+}
+
+// texture returns the texture for img, uploading it if it has not been drawn before or if it has
+// changed since it was uploaded.
+func (m *Manager) texture(r render.Renderer, img image.Image) render.Texture {
+	version := 0
+	if v, ok := img.(versioned); ok {
+		version = v.Version()
+	}
+	tex, ok := m.textures[img]
+	if ok && m.versions[img] == version {
+		return tex
+	}
+	rank := m.uploads
+	if ok {
+		tex.Release()
+		m.batch.Forget(tex)
+		rank = m.ranks[tex]
+		delete(m.ranks, tex)
+	} else {
+		m.uploads++
+	}
+	tex = r.NewTexture(img)
+	m.textures[img] = tex
+	m.versions[img] = version
+	m.ranks[tex] = rank
+	return tex
+}
+
 // Update moves animated sprites on to the next frame when appropriate and performs any required
-// operations once the animation is complete, such as advancing to the follow-up animation.
+// operations once the animation is complete, such as advancing to the follow-up animation. Frame
+// events and finished animations are passed on to the observers once every sprite has been
+// updated.
 func (m *Manager) Update(dt time.Duration, entities *entity.Manager) {
-	for _, e := range entities.All() {
-		if sprite, ok := e.Components["sprite"].(*Sprite); ok {
-			sprite.FrameTime += dt
-			for sprite.FrameTime*time.Duration(sprite.CurrentAnimation.Fps) >= time.Second {
-				sprite.FrameTime -= time.Second / time.Duration(sprite.CurrentAnimation.Fps)
-				sprite.CurrentFrame++
+	m.watch(entities)
+	store := Sprites(entities)
+	m.fired = m.fired[:0]
+	for i, sprite := range store.Components() {
+		m.advance(store.Entities()[i], sprite, dt)
+	}
+	for _, f := range m.fired {
+		if f.finished {
+			for _, observer := range m.finished {
+				observer(f.entity, f.name)
 			}
-			for sprite.CurrentFrame >= len(sprite.CurrentAnimation.Frames) {
-				sprite.CurrentFrame -= len(sprite.CurrentAnimation.Frames)
-				sprite.CurrentAnimation = sprite.CurrentAnimation.Next
+		} else {
+			for _, observer := range m.events[f.name] {
+				observer(f.entity)
 			}
 		}
 	}
 }
 
-// Draw draws the current frame of all entities with sprite components at the position given by the
-// pos component.
-func (m *Manager) Draw(c *camera.Camera, entities *entity.Manager) {
-	gl.Enable(gl.BLEND)
-	gl.Disable(gl.LIGHTING)
-	gl.TexEnvf(gl.TEXTURE_ENV, gl.TEXTURE_ENV_MODE, gl.REPLACE)
-	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
-	gl.Color4f(1.0, 1.0, 1.0, 1.0)
-	gl.Enable(gl.TEXTURE_2D)
-	for _, e := range entities.All() {
-		if pos, ok := e.Components["pos"].(*entity.Position); ok {
-			if sprite, ok := e.Components["sprite"].(*Sprite); ok {
-				if c.World.Intersects(&camera.Rectangle{
-					pos.X, pos.Y,
-					float32(sprite.Width),
-					float32(sprite.Height),
-				}) {
-					tex, ok := m.textures[sprite.Image]
-					if !ok {
-						tex = loadImageAsTexture(sprite.Image)
-						m.textures[sprite.Image] = tex
-					}
-
-					tw := float32(sprite.Width) / float32(sprite.Image.Bounds().Dx())
-					th := float32(sprite.Height) / float32(sprite.Image.Bounds().Dy())
-					n := int(1.0 / tw)
-					f := sprite.CurrentAnimation.Frames[sprite.CurrentFrame]
-					tx := tw * float32(f%n)
-					ty := th * float32(f/n)
-
-					x := int((pos.X - c.World.X) * float32(c.Screen.Width) / c.World.Width)
-					y := int((pos.Y - c.World.Y) * float32(c.Screen.Height) / c.World.Height)
-					w := int(float32(sprite.Width*c.Screen.Width) / c.World.Width)
-					h := int(float32(sprite.Height*c.Screen.Height) / c.World.Height)
-
-					tex.Bind(gl.TEXTURE_2D)
-					gl.Begin(gl.QUADS)
-
-					gl.TexCoord2f(tx, ty)
-					gl.Vertex2i(x, y)
-
-					gl.TexCoord2f(tx+tw, ty)
-					gl.Vertex2i(x+w, y)
-
-					gl.TexCoord2f(tx+tw, ty+th)
-					gl.Vertex2i(x+w, y+h)
-
-					gl.TexCoord2f(tx, ty+th)
-					gl.Vertex2i(x, y+h)
-
-					gl.End()
-					tex.Unbind(gl.TEXTURE_2D)
-				}
+// advance moves the sprite on by dt, recording any events that it fires.
+func (m *Manager) advance(e *entity.Entity, sprite *Sprite, dt time.Duration) {
+	if !sprite.Playing() {
+		return
+	}
+	if !sprite.Entered {
+		sprite.Entered = true
+		m.enter(e, sprite)
+	}
+	sprite.FrameTime += dt
+	for {
+		a := sprite.CurrentAnimation
+		d := a.FrameDuration(sprite.CurrentFrame)
+		if d <= 0 || sprite.FrameTime < d {
+			return
+		}
+		sprite.FrameTime -= d
+		if sprite.CurrentFrame+1 < len(a.Frames) {
+			sprite.CurrentFrame++
+		} else {
+			next := a.Next
+			if len(sprite.queue) > 0 {
+				next = sprite.queue[0]
+				sprite.queue = sprite.queue[1:]
+			}
+			if next == nil {
+				sprite.stopped = true
+				sprite.FrameTime = 0
+				m.fired = append(m.fired, fired{e, sprite.AnimationName(), true})
+				return
 			}
+			sprite.CurrentAnimation = next
+			sprite.CurrentFrame = 0
 		}
+		m.enter(e, sprite)
+	}
+}
+
+// enter records the events for the frame the sprite has just reached.
+func (m *Manager) enter(e *entity.Entity, sprite *Sprite) {
+	for _, name := range sprite.CurrentAnimation.Events[sprite.CurrentFrame] {
+		m.fired = append(m.fired, fired{e, name, false})
+	}
+}
+
+// fired is an animation event waiting to be passed on to observers.
+type fired struct {
+	entity   *entity.Entity
+	name     string
+	finished bool
+}
+
+// OnEvent registers f to be called whenever a sprite reaches a frame marked with the named event.
+func (m *Manager) OnEvent(event string, f func(e *entity.Entity)) {
+	m.events[event] = append(m.events[event], f)
+}
+
+// OnFinished registers f to be called whenever a sprite finishes an animation that has no
+// follow-up, with the name of the animation.
+func (m *Manager) OnFinished(f func(e *entity.Entity, animation string)) {
+	m.finished = append(m.finished, f)
+}
+
+// SetLayer changes the settings for drawing the numbered layer.
+func (m *Manager) SetLayer(n int, l Layer) {
+	m.layers[n] = l
+}
+
+// Layer returns the settings for drawing the numbered layer, which are DefaultLayer unless they
+// have been changed by SetLayer.
+func (m *Manager) Layer(n int) Layer {
+	if l, ok := m.layers[n]; ok {
+		return l
 	}
+	return DefaultLayer
 }
 
-type rgba struct {
-	r, g, b, a uint16
+// visible is a sprite that is on screen this frame, waiting to be sorted into drawing order.
+type visible struct {
+	entity   *entity.Entity
+	sprite   *Sprite
+	layer    int
+	z, y     float32
+	px, py   float32
+	corners  [4][2]float32
+	texture  render.Texture
+	rank     int
+	settings Layer
+	view     *view
+}
+
+// view is the camera as seen by a layer, moved according to the layer's parallax.
+type view struct {
+	camera camera.Camera
+	matrix camera.Matrix
+}
+
+// layerView returns the view of the camera for the numbered layer, which is reused for the rest
+// of the frame.
+func (m *Manager) layerView(c *camera.Camera, layer int, settings Layer) *view {
+	v, ok := m.views[layer]
+	if !ok {
+		v = new(view)
+		m.views[layer] = v
+	}
+	v.camera = *c
+	px, py := settings.parallax()
+	v.camera.World.X *= px
+	v.camera.World.Y *= py
+	v.matrix = v.camera.ViewProjection()
+	return v
 }
 
-func loadImageAsTexture(img image.Image) gl.Texture {
-	tex := gl.GenTexture()
-	tex.Bind(gl.TEXTURE_2D)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
-	w := img.Bounds().Dx()
-	h := img.Bounds().Dy()
-	data := make([]rgba, w*h)
-	for y := 0; y < h; y++ {
-		for x := 0; x < w; x++ {
-			r, g, b, a := img.At(x, y).RGBA()
-			data[x+y*w].r = uint16(r)
-			data[x+y*w].g = uint16(g)
-			data[x+y*w].b = uint16(b)
-			data[x+y*w].a = uint16(a)
+// drawsBefore orders visible sprites by layer and then by the layer's sort mode.
+func drawsBefore(a, b *visible) bool {
+	if a.layer != b.layer {
+		return a.layer < b.layer
+	}
+	switch a.settings.Sort {
+	case SortY:
+		if a.y != b.y {
+			return a.y < b.y
+		}
+		if a.z != b.z {
+			return a.z < b.z
+		}
+	case SortZ:
+		if a.z != b.z {
+			return a.z < b.z
 		}
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+	case SortTexture:
+		return false
 	}
-	gl.TexImage2D(
-		gl.TEXTURE_2D,     // target
-		0,                 // level, 0 = base, no mipmap,
-		gl.RGBA,           // internal format
-		w,                 // width
-		h,                 // height
-		0,                 // border
-		gl.RGBA,           // format
-		gl.UNSIGNED_SHORT, // type
-		data,              // image
-	)
-	tex.Unbind(gl.TEXTURE_2D)
-	return tex
+	return a.entity.Before(b.entity)
+}
+
+// Draw draws the current frame of all entities with sprite components at the position given by the
+// pos component, rotated, scaled and flipped by the transform component if they have one, as seen
+// through the camera's view and projection. Sprites are drawn layer by layer, skipping layers the
+// camera does not show, ordered within each layer by its sort mode. Neighbouring sprites in the
+// drawing order that share a texture are batched into a single draw call, so layers sorted by
+// texture, and sprites with the same Z in layers sorted by Z, are drawn with a single call for
+// each texture.
+func (m *Manager) Draw(r render.Renderer, c *camera.Camera, entities *entity.Manager) {
+	m.watch(entities)
+	sprites := Sprites(entities)
+	positions := entity.Positions(entities)
+	depths := Depths(entities)
+	transforms := entity.Transforms(entities)
+	m.visible = m.visible[:0]
+	for layer := range m.views {
+		delete(m.views, layer)
+	}
+	for _, e := range entities.View(entity.With("pos", "sprite")).Entities() {
+		sprite, _ := sprites.Get(e)
+		pos, _ := positions.Get(e)
+		v := visible{entity: e, sprite: sprite}
+		if depth, ok := depths.Get(e); ok {
+			v.layer, v.z = depth.Layer, depth.Z
+		}
+		if !c.Shows(v.layer) {
+			continue
+		}
+		v.settings = m.Layer(v.layer)
+		v.view = m.views[v.layer]
+		if v.view == nil {
+			v.view = m.layerView(c, v.layer, v.settings)
+		}
+
+		transform, _ := transforms.Get(e)
+		bounds := sprite.Bounds()
+		x0, y0 := float32(bounds.Min.X), float32(bounds.Min.Y)
+		x1, y1 := float32(bounds.Max.X), float32(bounds.Max.Y)
+		var minX, minY, maxX, maxY float32
+		for i, corner := range [4][2]float32{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}} {
+			x, y := transform.Apply(corner[0], corner[1])
+			v.corners[i] = [2]float32{x, y}
+			if i == 0 || x < minX {
+				minX = x
+			}
+			if i == 0 || x > maxX {
+				maxX = x
+			}
+			if i == 0 || y < minY {
+				minY = y
+			}
+			if i == 0 || y > maxY {
+				maxY = y
+			}
+		}
+		if v.view.camera.Visible(&camera.Rectangle{
+			X:      pos.X + minX,
+			Y:      pos.Y + minY,
+			Width:  maxX - minX,
+			Height: maxY - minY,
+		}) {
+			v.px, v.py = pos.X, pos.Y
+			v.y = pos.Y + maxY
+			v.texture = m.texture(r, sprite.Image)
+			v.rank = m.ranks[v.texture]
+			m.visible = append(m.visible, v)
+		}
+	}
+	sort.SliceStable(m.visible, func(i, j int) bool {
+		return drawsBefore(&m.visible[i], &m.visible[j])
+	})
+
+	var last *visible
+	for i := range m.visible {
+		v := &m.visible[i]
+		if last != nil && (v.layer != last.layer || v.settings.Sort != SortTexture && v.texture != last.texture) {
+			m.batch.Draw(r)
+		}
+		last = v
+		sprite := v.sprite
+
+		b := sprite.Image.Bounds()
+		f := sprite.Frame().Sub(b.Min)
+		tx := float32(f.Min.X) / float32(b.Dx())
+		ty := float32(f.Min.Y) / float32(b.Dy())
+		tw := float32(f.Dx()) / float32(b.Dx())
+		th := float32(f.Dy()) / float32(b.Dy())
+		red, green, blue, alpha := sprite.tint()
+
+		// The position is snapped to whole pixels so that unrotated sprites stay crisp.
+		x, y := v.view.matrix.Apply(v.px, v.py)
+		x, y = snap(x), snap(y)
+		var quad [4]render.Vertex
+		for j, uv := range [4][2]float32{{tx, ty}, {tx + tw, ty}, {tx + tw, ty + th}, {tx, ty + th}} {
+			cx, cy := v.view.matrix.ApplyVector(v.corners[j][0], v.corners[j][1])
+			quad[j] = render.Vertex{
+				X: x + cx, Y: y + cy,
+				U: uv[0], V: uv[1],
+				R: red, G: green, B: blue, A: alpha,
+			}
+		}
+		m.batch.Add(v.texture, quad)
+	}
+	m.batch.Draw(r)
+}
+
+// snap rounds a screen coordinate to the nearest whole pixel.
+func snap(x float32) float32 {
+	return float32(math.Floor(float64(x) + 0.5))
 }
@@ -0,0 +1,247 @@
+package sprite
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/FinnStokes/huge/camera"
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/render"
+)
+
+// counter is a headless renderer that counts the draw calls made with each texture.
+type counter struct {
+	*render.Headless
+	calls map[render.Texture]int
+	quads int
+}
+
+func (c *counter) DrawQuads(tex render.Texture, quads []render.Vertex) {
+	c.calls[tex]++
+	c.quads += len(quads) / 4
+	c.Headless.DrawQuads(tex, quads)
+}
+
+func newImage(c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestBatch(t *testing.T) {
+	h := render.NewHeadless(100, 100)
+	h.Open(100, 100, "")
+	r := &counter{Headless: h, calls: make(map[render.Texture]int)}
+	c := &camera.Camera{World: camera.Rectangle{Width: 100, Height: 100}, Screen: camera.Screen{Width: 100, Height: 100}}
+	entities := entity.NewManager()
+	images := []image.Image{newImage(color.RGBA{255, 0, 0, 255}), newImage(color.RGBA{0, 0, 255, 255})}
+	for i := 0; i < 100; i++ {
+		e := entities.New()
+		x := float32(i%10) * 10
+		if i >= 90 {
+			x = 200
+		}
+		entity.Positions(entities).Set(e, &entity.Position{X: x, Y: float32(i/10) * 10})
+		animation := &Animation{Frames: []int{0}, Fps: 1}
+		animation.Next = animation
+		Sprites(entities).Set(e, &Sprite{Image: images[i%2], Width: 8, Height: 8, CurrentAnimation: animation})
+	}
+	m := NewManager()
+	m.Draw(r, c, entities)
+	if len(r.calls) != 2 {
+		t.Fatalf("Drew with %v textures, expected 2", len(r.calls))
+	}
+	for tex, n := range r.calls {
+		if n != 1 {
+			t.Errorf("Drew texture %v in %v calls, expected 1", tex, n)
+		}
+	}
+	if r.quads != 90 {
+		t.Errorf("Drew %v quads, expected 90 visible", r.quads)
+	}
+	h.SwapBuffers()
+	if p := h.Image().RGBAAt(12, 2); p != (color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("Pixel of second sprite is %v, expected blue", p)
+	}
+}
+
+// newSprite adds an entity with a solid 8x8 sprite of the given colour at the position.
+func newSprite(entities *entity.Manager, x, y float32, c color.Color) *entity.Entity {
+	e := entities.New()
+	entity.Positions(entities).Set(e, &entity.Position{X: x, Y: y})
+	animation := &Animation{Frames: []int{0}, Fps: 1}
+	animation.Next = animation
+	Sprites(entities).Set(e, &Sprite{Image: newImage(c), Width: 8, Height: 8, CurrentAnimation: animation})
+	return e
+}
+
+func TestUnanimated(t *testing.T) {
+	h := render.NewHeadless(100, 100)
+	h.Open(100, 100, "")
+	c := &camera.Camera{World: camera.Rectangle{Width: 100, Height: 100}, Screen: camera.Screen{Width: 100, Height: 100}}
+	entities := entity.NewManager()
+	red := color.RGBA{255, 0, 0, 255}
+	e := entities.New()
+	entity.Positions(entities).Set(e, &entity.Position{X: 10, Y: 10})
+	Sprites(entities).Set(e, &Sprite{Image: newImage(red)})
+
+	m := NewManager()
+	m.Update(time.Second, entities)
+	m.Draw(h, c, entities)
+	h.SwapBuffers()
+	if got := h.Image().RGBAAt(17, 17); got != red {
+		t.Errorf("Pixel at 17,17 is %v, expected the whole image drawn in %v", got, red)
+	}
+}
+
+func TestLayers(t *testing.T) {
+	h := render.NewHeadless(100, 100)
+	h.Open(100, 100, "")
+	c := &camera.Camera{World: camera.Rectangle{X: 20, Y: 0, Width: 100, Height: 100}, Screen: camera.Screen{Width: 100, Height: 100}}
+	red, green, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}, color.RGBA{0, 0, 255, 255}
+	entities := entity.NewManager()
+	depths := Depths(entities)
+
+	// Sorted by Z, the earlier red sprite is drawn over the green one.
+	depths.Set(newSprite(entities, 20, 0, red), &Depth{Z: 1})
+	newSprite(entities, 24, 4, green)
+	// Sorted by Y in a higher layer, the earlier sprite lower down is drawn in front.
+	depths.Set(newSprite(entities, 40, 4, red), &Depth{Layer: 2})
+	depths.Set(newSprite(entities, 44, 0, green), &Depth{Layer: 2, Z: 5})
+	// A higher layer fixed to the screen is drawn over everything else.
+	depths.Set(newSprite(entities, 2, 2, blue), &Depth{Layer: 3})
+
+	m := NewManager()
+	m.SetLayer(2, Layer{Sort: SortY})
+	m.SetLayer(3, Layer{Sort: SortInsertion, Fixed: true})
+	m.Draw(h, c, entities)
+	h.SwapBuffers()
+	for _, p := range []struct {
+		x, y int
+		c    color.RGBA
+	}{
+		{1, 1, red},
+		{2, 2, blue},
+		{9, 9, blue},
+		{11, 11, green},
+		{25, 5, red},
+		{29, 2, green},
+	} {
+		if got := h.Image().RGBAAt(p.x, p.y); got != p.c {
+			t.Errorf("Pixel at %v,%v is %v, expected %v", p.x, p.y, got, p.c)
+		}
+	}
+}
+
+func TestTransform(t *testing.T) {
+	h := render.NewHeadless(100, 100)
+	h.Open(100, 100, "")
+	c := &camera.Camera{World: camera.Rectangle{Width: 100, Height: 100}, Screen: camera.Screen{Width: 100, Height: 100}}
+	entities := entity.NewManager()
+	transforms := entity.Transforms(entities)
+	red, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}
+
+	// The left half of the image is red and the right half blue.
+	flipped := newSprite(entities, 10, 10, red)
+	s, _ := Sprites(entities).Get(flipped)
+	img := s.Image.(*image.RGBA)
+	for y := 0; y < 8; y++ {
+		for x := 4; x < 8; x++ {
+			img.Set(x, y, blue)
+		}
+	}
+	transforms.Set(flipped, &entity.Transform{FlipX: true, OriginX: 4})
+	// A quarter turn about the top left corner brings the sprite into view from off screen.
+	rotated := newSprite(entities, 108, 50, red)
+	transforms.Set(rotated, &entity.Transform{Rotation: math.Pi / 2, ScaleX: 2, ScaleY: 2})
+	tinted := newSprite(entities, 50, 10, color.RGBA{255, 255, 255, 255})
+	s, _ = Sprites(entities).Get(tinted)
+	s.Tint = color.NRGBA{255, 0, 0, 255}
+
+	NewManager().Draw(h, c, entities)
+	h.SwapBuffers()
+	for _, p := range []struct {
+		x, y int
+		c    color.RGBA
+	}{
+		{11, 11, blue},
+		{16, 11, red},
+		{93, 51, red},
+		{93, 65, red},
+		{99, 67, color.RGBA{}},
+		{51, 11, red},
+	} {
+		if got := h.Image().RGBAAt(p.x, p.y); got != p.c {
+			t.Errorf("Pixel at %v,%v is %v, expected %v", p.x, p.y, got, p.c)
+		}
+	}
+}
+
+func TestRotatedCamera(t *testing.T) {
+	h := render.NewHeadless(100, 100)
+	h.Open(100, 100, "")
+	c := &camera.Camera{World: camera.Rectangle{Width: 100, Height: 100}, Screen: camera.Screen{Width: 100, Height: 100}, Rotation: math.Pi}
+	entities := entity.NewManager()
+	red := color.RGBA{255, 0, 0, 255}
+	newSprite(entities, 10, 10, red)
+
+	NewManager().Draw(h, c, entities)
+	h.SwapBuffers()
+	// A half turn about the centre of the view moves the sprite to the opposite corner.
+	if got := h.Image().RGBAAt(85, 85); got != red {
+		t.Errorf("Pixel at 85,85 is %v, expected %v", got, red)
+	}
+	if got := h.Image().RGBAAt(13, 13); got != (color.RGBA{}) {
+		t.Errorf("Pixel at 13,13 is %v, expected nothing", got)
+	}
+}
+
+// releaser is a headless renderer whose textures count how many times they are released.
+type releaser struct {
+	*render.Headless
+	released int
+}
+
+type releasedTexture struct {
+	render.Texture
+	r *releaser
+}
+
+func (t *releasedTexture) Release() {
+	t.r.released++
+}
+
+func (r *releaser) NewTexture(img image.Image) render.Texture {
+	return &releasedTexture{r.Headless.NewTexture(img), r}
+}
+
+func TestClose(t *testing.T) {
+	h := render.NewHeadless(100, 100)
+	h.Open(100, 100, "")
+	r := &releaser{Headless: h}
+	c := &camera.Camera{World: camera.Rectangle{Width: 100, Height: 100}, Screen: camera.Screen{Width: 100, Height: 100}}
+	entities := entity.NewManager()
+	e := newSprite(entities, 10, 10, color.RGBA{255, 0, 0, 255})
+	m := NewManager()
+	m.Draw(r, c, entities)
+	m.Close(entities)
+	if r.released != 1 || len(m.watched) != 0 {
+		t.Errorf("Closing released %v textures and kept %v managers, expected 1 and 0", r.released, len(m.watched))
+	}
+	Sprites(entities).Remove(e)
+	if r.released != 1 || len(m.users) != 0 {
+		t.Errorf("Removing a sprite after closing changed the counts")
+	}
+	newSprite(entities, 10, 10, color.RGBA{255, 0, 0, 255})
+	m.Draw(r, c, entities)
+	if len(m.textures) != 1 || m.users[Sprites(entities).Components()[0].Image] != 1 {
+		t.Errorf("Drawing closed entities again did not count their sprites")
+	}
+}
@@ -2,20 +2,182 @@ package sprite
 
 import (
 	"image"
+	"image/color"
 	"time"
+
+	"github.com/FinnStokes/huge/entity"
 )
 
 type Sprite struct {
-	Image            image.Image
-	Animations       map[string]*Animation
-	Width, Height    int
+	// Name is the name of the resource the sprite was loaded from, if any.
+	Name       string
+	Image      image.Image
+	Animations map[string]*Animation
+	// Regions gives the bounds within Image of each numbered frame. If it is empty, the frames
+	// are instead laid out in a grid of Width by Height cells, left to right and top to bottom.
+	Regions []image.Rectangle
+	// Offsets gives where the top left of each region is drawn relative to the sprite's top left
+	// corner, for frames that have been trimmed of their transparent borders.
+	Offsets       []image.Point
+	Width, Height int
+	// Tint is multiplied with the sprite's image when it is drawn, and its alpha fades the sprite
+	// out. A nil tint draws the image unchanged.
+	Tint             color.Color
 	CurrentAnimation *Animation
 	CurrentFrame     int
 	FrameTime        time.Duration
+	// Entered records that the events of the current frame have fired. It is false for a newly
+	// built sprite and whenever an animation starts, so that the first frame's events fire on the
+	// sprite's next update.
+	Entered bool
+	queue   []*Animation
+	stopped bool
+}
+
+// AnimationName returns the name under which the current animation is stored in Animations, or
+// the empty string if it is not found.
+func (s *Sprite) AnimationName() string {
+	for name, a := range s.Animations {
+		if a == s.CurrentAnimation {
+			return name
+		}
+	}
+	return ""
+}
+
+// tint returns the components of the sprite's tint as unpremultiplied values between 0 and 1.
+func (s *Sprite) tint() (r, g, b, a float32) {
+	if s.Tint == nil {
+		return 1, 1, 1, 1
+	}
+	c := color.NRGBAModel.Convert(s.Tint).(color.NRGBA)
+	return float32(c.R) / 255, float32(c.G) / 255, float32(c.B) / 255, float32(c.A) / 255
+}
+
+// frame returns the index in the image of the current frame, which is frame 0 if the sprite has
+// no animation.
+func (s *Sprite) frame() int {
+	a := s.CurrentAnimation
+	if a == nil || s.CurrentFrame < 0 || s.CurrentFrame >= len(a.Frames) {
+		return 0
+	}
+	return a.Frames[s.CurrentFrame]
+}
+
+// Frame returns the bounds within Image of the current frame. A sprite with neither regions nor
+// a width and height has a single frame covering the whole image.
+func (s *Sprite) Frame() image.Rectangle {
+	f := s.frame()
+	if len(s.Regions) > 0 {
+		return s.Regions[f]
+	}
+	b := s.Image.Bounds()
+	if s.Width <= 0 || s.Height <= 0 {
+		return b
+	}
+	n := b.Dx() / s.Width
+	if n < 1 {
+		n = 1
+	}
+	min := b.Min.Add(image.Pt(f%n*s.Width, f/n*s.Height))
+	return image.Rectangle{min, min.Add(image.Pt(s.Width, s.Height))}
+}
+
+// Play starts the named animation from its first frame and clears any queued animations. If the
+// animation is already playing it carries on uninterrupted, so Play can be called every tick. It
+// returns false if the sprite has no animation with that name.
+func (s *Sprite) Play(name string) bool {
+	a, ok := s.Animations[name]
+	if !ok {
+		return false
+	}
+	s.queue = nil
+	if a == s.CurrentAnimation && !s.stopped {
+		return true
+	}
+	s.start(a)
+	return true
+}
+
+// Queue plays the named animation once the current one finishes, in place of its follow-up
+// animation. Animations queued one after another play in turn. If nothing is playing, the
+// animation starts immediately. It returns false if the sprite has no animation with that name.
+func (s *Sprite) Queue(name string) bool {
+	a, ok := s.Animations[name]
+	if !ok {
+		return false
+	}
+	if !s.Playing() {
+		s.start(a)
+	} else {
+		s.queue = append(s.queue, a)
+	}
+	return true
 }
 
+// Stop pauses the sprite on its current frame and clears any queued animations.
+func (s *Sprite) Stop() {
+	s.stopped = true
+	s.queue = nil
+}
+
+// Playing returns true unless the sprite has been stopped or has finished an animation that has
+// no follow-up.
+func (s *Sprite) Playing() bool {
+	return !s.stopped && s.CurrentAnimation != nil
+}
+
+// start plays a from its first frame.
+func (s *Sprite) start(a *Animation) {
+	s.CurrentAnimation = a
+	s.CurrentFrame = 0
+	s.FrameTime = 0
+	s.stopped = false
+	s.Entered = false
+}
+
+// Bounds returns the rectangle that the current frame covers when drawn, relative to the
+// sprite's top left corner. Frames cover the sprite's whole width and height unless they are
+// regions of a different size or have been trimmed.
+func (s *Sprite) Bounds() image.Rectangle {
+	f := s.frame()
+	if len(s.Regions) == 0 {
+		return image.Rectangle{Max: s.Frame().Size()}
+	}
+	var offset image.Point
+	if f < len(s.Offsets) {
+		offset = s.Offsets[f]
+	}
+	return image.Rectangle{offset, offset.Add(s.Regions[f].Size())}
+}
+
+// Animation is a sequence of frames, given by their index in the sprite's image.
 type Animation struct {
 	Frames []int
 	Fps    int
-	Next   *Animation
+	// Durations gives how long each frame is shown for, in place of Fps. Frames past the end of
+	// Durations are shown for a period set by Fps.
+	Durations []time.Duration
+	// Events names the events that are fired when the sprite reaches each position in Frames.
+	Events map[int][]string
+	// Next is the animation that follows this one. If it is nil, the sprite stops on the last
+	// frame once the animation finishes.
+	Next *Animation
+}
+
+// FrameDuration returns how long the frame at position i in Frames is shown for, or zero if the
+// animation does not advance past it.
+func (a *Animation) FrameDuration(i int) time.Duration {
+	if i < len(a.Durations) {
+		return a.Durations[i]
+	}
+	if a.Fps > 0 {
+		return time.Second / time.Duration(a.Fps)
+	}
+	return 0
+}
+
+// Sprites returns the entity manager's store of sprite components, registered as "sprite".
+func Sprites(m *entity.Manager) *entity.ComponentStore[*Sprite] {
+	return entity.Register[*Sprite](m, "sprite")
 }
@@ -0,0 +1,121 @@
+package sprite
+
+import (
+	"image"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/FinnStokes/huge/entity"
+)
+
+func TestAnimation(t *testing.T) {
+	walk := &Animation{
+		Frames:    []int{0, 1, 2},
+		Durations: []time.Duration{10 * time.Millisecond, 30 * time.Millisecond},
+		Fps:       50,
+		Events:    map[int][]string{0: {"step"}, 2: {"step", "dust"}},
+	}
+	walk.Next = walk
+	attack := &Animation{Frames: []int{3, 4}, Fps: 100, Events: map[int][]string{1: {"hit"}}}
+	s := &Sprite{Animations: map[string]*Animation{"walk": walk, "attack": attack}}
+
+	entities := entity.NewManager()
+	e := entities.New()
+	Sprites(entities).Set(e, s)
+	m := NewManager()
+	var events []string
+	for _, name := range []string{"step", "dust", "hit"} {
+		name := name
+		m.OnEvent(name, func(got *entity.Entity) {
+			if got != e {
+				t.Errorf("Event %v fired for entity %v, expected %v", name, got.Id(), e.Id())
+			}
+			events = append(events, name)
+		})
+	}
+	m.OnFinished(func(got *entity.Entity, animation string) {
+		events = append(events, "finished "+animation)
+	})
+	tick := func(n int) {
+		for i := 0; i < n; i++ {
+			m.Update(10*time.Millisecond, entities)
+		}
+	}
+
+	if s.Play("run") {
+		t.Error("Played missing animation")
+	}
+	s.Play("walk")
+	tick(1)
+	if s.CurrentFrame != 1 || !reflect.DeepEqual(events, []string{"step"}) {
+		t.Errorf("After 10ms on frame %v with events %v, expected frame 1 and a step", s.CurrentFrame, events)
+	}
+	tick(3)
+	s.Play("walk")
+	if s.CurrentFrame != 2 {
+		t.Errorf("After 40ms on frame %v, expected 2", s.CurrentFrame)
+	}
+	tick(2)
+	if s.CurrentFrame != 0 || !reflect.DeepEqual(events, []string{"step", "step", "dust", "step"}) {
+		t.Errorf("After 60ms on frame %v with events %v, expected to loop to frame 0", s.CurrentFrame, events)
+	}
+
+	events = nil
+	s.Queue("attack")
+	tick(8)
+	if s.CurrentAnimation != attack || s.CurrentFrame != 1 || s.Playing() {
+		t.Errorf("On %v frame %v, playing %v, expected to stop at the end of attack", s.AnimationName(), s.CurrentFrame, s.Playing())
+	}
+	if expected := []string{"step", "dust", "hit", "finished attack"}; !reflect.DeepEqual(events, expected) {
+		t.Errorf("Events %v, expected %v", events, expected)
+	}
+
+	s.Play("walk")
+	s.Stop()
+	tick(10)
+	if s.CurrentFrame != 0 || s.Playing() {
+		t.Errorf("Stopped sprite moved to frame %v", s.CurrentFrame)
+	}
+
+	// A sprite given its animation directly, as when loaded as a resource, fires the events of
+	// its first frame too.
+	events = nil
+	Sprites(entities).Remove(e)
+	e = entities.New()
+	Sprites(entities).Set(e, &Sprite{Animations: s.Animations, CurrentAnimation: walk})
+	tick(1)
+	if !reflect.DeepEqual(events, []string{"step"}) {
+		t.Errorf("Loaded sprite fired events %v, expected a step", events)
+	}
+
+	// A sprite restored part way through a frame whose events have already fired, or watched
+	// again after its scene is closed, does not fire them again.
+	events = nil
+	restored := &Sprite{Animations: s.Animations, CurrentAnimation: walk, CurrentFrame: 2, Entered: true}
+	Sprites(entities).Remove(e)
+	e = entities.New()
+	Sprites(entities).Set(e, restored)
+	tick(1)
+	m.Close(entities)
+	m.Update(5*time.Millisecond, entities)
+	if len(events) != 0 || restored.CurrentFrame != 2 {
+		t.Errorf("Restored sprite fired events %v on frame %v, expected none on frame 2", events, restored.CurrentFrame)
+	}
+}
+
+func TestFrame(t *testing.T) {
+	a := &Animation{Frames: []int{0, 5}, Fps: 1}
+	s := &Sprite{Image: image.NewRGBA(image.Rect(0, 0, 32, 16)), CurrentAnimation: a}
+	if f := s.Frame(); f != image.Rect(0, 0, 32, 16) || s.Bounds() != f {
+		t.Errorf("Sprite without a size has frame %v and bounds %v, expected the whole image", f, s.Bounds())
+	}
+	s.Width, s.Height, s.CurrentFrame = 8, 8, 1
+	if f := s.Frame(); f != image.Rect(8, 8, 16, 16) {
+		t.Errorf("Frame 5 of grid is %v, expected (8,8)-(16,16)", f)
+	}
+	s.Width = 64
+	if f := s.Frame(); f != image.Rect(0, 40, 64, 48) {
+		t.Errorf("Frame 5 of grid wider than the image is %v, expected (0,40)-(64,48)", f)
+	}
+}
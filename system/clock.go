@@ -0,0 +1,102 @@
+package system
+
+import "time"
+
+// Clock drives the updates of a system manager using fixed timesteps. Simulated time advances in
+// whole ticks, and systems at each speed are updated with exactly their configured period as dt,
+// so that a game given the same inputs always performs the same sequence of updates regardless of
+// how quickly it is actually running.
+type Clock struct {
+	// Tick is the unit by which simulated time advances.
+	Tick time.Duration
+	// MaxTicks limits how many ticks a single call to Advance may run, so that a game which falls
+	// behind drops time rather than spending ever longer catching up.
+	MaxTicks int
+	// BeforeTick, if not nil, is called at the start of each tick before any systems are
+	// updated, such as to poll input.
+	BeforeTick func()
+	periods    [NumSpeeds]time.Duration
+	elapsed    [NumSpeeds]time.Duration
+	pending    time.Duration
+	ticks      int64
+}
+
+// NewClock returns a clock with a 10ms tick, updating Fast systems every tick, Normal systems
+// every 20ms and Slow systems every second.
+func NewClock() *Clock {
+	c := new(Clock)
+	c.Tick = 10 * time.Millisecond
+	c.MaxTicks = 25
+	c.periods[Slow] = time.Second
+	c.periods[Normal] = 20 * time.Millisecond
+	c.periods[Fast] = 10 * time.Millisecond
+	return c
+}
+
+// SetSpeed sets the interval between updates of systems at the given speed.
+func (c *Clock) SetSpeed(speed Speed, period time.Duration) {
+	c.periods[speed] = period
+}
+
+// Period returns the interval between updates of systems at the given speed.
+func (c *Clock) Period(speed Speed) time.Duration {
+	return c.periods[speed]
+}
+
+// Ticks returns the number of ticks that have been run.
+func (c *Clock) Ticks() int64 {
+	return c.ticks
+}
+
+// Time returns the total simulated time that has passed.
+func (c *Clock) Time() time.Duration {
+	return time.Duration(c.ticks) * c.Tick
+}
+
+// Advance accumulates real elapsed time and runs as many whole ticks as it covers, returning the
+// number of ticks run. Time left over is carried forward to the next call. A clock with no tick
+// never advances.
+func (c *Clock) Advance(elapsed time.Duration, update func(speed Speed, dt time.Duration)) int {
+	if c.Tick <= 0 {
+		return 0
+	}
+	c.pending += elapsed
+	n := int(c.pending / c.Tick)
+	c.pending -= time.Duration(n) * c.Tick
+	if c.MaxTicks > 0 && n > c.MaxTicks {
+		n = c.MaxTicks
+	}
+	c.Step(n, update)
+	return n
+}
+
+// Remaining returns how much more real time must be passed to Advance before it runs the next
+// tick, or zero if the clock has no tick.
+func (c *Clock) Remaining() time.Duration {
+	if c.Tick <= 0 || c.pending >= c.Tick {
+		return 0
+	}
+	return c.Tick - c.pending
+}
+
+// Step runs n ticks immediately, independent of real time, calling update for each speed that
+// is due. Within a tick, speeds are updated in order from Slow to Fast, with a speed updated more
+// than once if its period is shorter than the tick.
+func (c *Clock) Step(n int, update func(speed Speed, dt time.Duration)) {
+	for i := 0; i < n; i++ {
+		c.ticks++
+		if c.BeforeTick != nil {
+			c.BeforeTick()
+		}
+		for s := range c.periods {
+			if c.periods[s] <= 0 {
+				continue
+			}
+			c.elapsed[s] += c.Tick
+			for c.elapsed[s] >= c.periods[s] {
+				c.elapsed[s] -= c.periods[s]
+				update(Speed(s), c.periods[s])
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package system
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FinnStokes/huge/camera"
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/render"
+)
+
+type update struct {
+	name string
+	dt   time.Duration
+}
+
+// recorder is a system that appends each of its updates to a shared log.
+type recorder struct {
+	name string
+	log  *[]update
+}
+
+func (r *recorder) Update(dt time.Duration, entities *entity.Manager) {
+	*r.log = append(*r.log, update{r.name, dt})
+}
+
+func (r *recorder) Draw(rend render.Renderer, c *camera.Camera, entities *entity.Manager) {}
+
+// newRecordedUpdate returns a function that updates a manager of recorders.
+func newRecordedUpdate(log *[]update) func(speed Speed, dt time.Duration) {
+	m := NewManager()
+	m.AddSystem(Fast, &recorder{"fast", log})
+	m.AddSystem(Normal, &recorder{"normal", log})
+	m.AddSystem(Slow, &recorder{"slow", log})
+	return func(speed Speed, dt time.Duration) {
+		m.Update(speed, dt, nil)
+	}
+}
+
+func TestStep(t *testing.T) {
+	var log []update
+	c := NewClock()
+	c.Step(100, newRecordedUpdate(&log))
+	counts := make(map[string]int)
+	for _, u := range log {
+		counts[u.name]++
+	}
+	if counts["fast"] != 100 || counts["normal"] != 50 || counts["slow"] != 1 {
+		t.Errorf("Wrong number of updates: %v", counts)
+	}
+	for _, u := range log {
+		var want time.Duration
+		switch u.name {
+		case "fast":
+			want = 10 * time.Millisecond
+		case "normal":
+			want = 20 * time.Millisecond
+		case "slow":
+			want = time.Second
+		}
+		if u.dt != want {
+			t.Errorf("Update of %v has dt %v, expected %v", u.name, u.dt, want)
+		}
+	}
+	last := log[len(log)-3:]
+	if last[0].name != "slow" || last[1].name != "normal" || last[2].name != "fast" {
+		t.Errorf("Updates in final tick out of order: %v", last)
+	}
+	if c.Ticks() != 100 || c.Time() != time.Second {
+		t.Errorf("Clock at tick %v (%v), expected 100 (1s)", c.Ticks(), c.Time())
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	var stepped, advanced []update
+	c := NewClock()
+	c.Step(37, newRecordedUpdate(&stepped))
+
+	c = NewClock()
+	u := newRecordedUpdate(&advanced)
+	n := 0
+	for _, dt := range []time.Duration{3, 15, 7, 1, 99, 120, 25} {
+		n += c.Advance(dt*time.Millisecond, u)
+	}
+	if n != 27 || c.Ticks() != 27 {
+		t.Errorf("Advanced %v ticks (clock says %v), expected 27", n, c.Ticks())
+	}
+	n += c.Advance(100*time.Millisecond, u)
+	if n != 37 {
+		t.Errorf("Advanced %v ticks, expected 37", n)
+	}
+	if len(stepped) != len(advanced) {
+		t.Fatalf("Advancing gave %v updates, stepping gave %v", len(advanced), len(stepped))
+	}
+	for i := range stepped {
+		if stepped[i] != advanced[i] {
+			t.Errorf("Update %v differs: %v vs %v", i, advanced[i], stepped[i])
+		}
+	}
+
+	if n := c.Advance(time.Hour, u); n != c.MaxTicks {
+		t.Errorf("Advanced %v ticks, expected to be limited to %v", n, c.MaxTicks)
+	}
+	if n := c.Advance(0, u); n != 0 {
+		t.Errorf("Advanced %v ticks after dropping time, expected 0", n)
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	var log []update
+	c := NewClock()
+	if c.Advance(4*time.Millisecond, newRecordedUpdate(&log)); c.Remaining() != 6*time.Millisecond {
+		t.Errorf("Expected 6ms until the next tick, got %v", c.Remaining())
+	}
+	c.Tick = 0
+	if n := c.Advance(time.Second, newRecordedUpdate(&log)); n != 0 || c.Remaining() != 0 {
+		t.Errorf("Clock with no tick advanced %v ticks with %v remaining, expected none", n, c.Remaining())
+	}
+}
@@ -5,10 +5,24 @@ import (
 
 	"github.com/FinnStokes/huge/camera"
 	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/render"
+)
+
+// FlushMode determines how often the deferred changes to the entity manager are applied
+// during an update.
+type FlushMode int
+
+// FlushAfterSystem applies deferred changes after each system is updated, so that later systems
+// see the changes made by earlier ones in the same update. FlushAfterUpdate applies them only
+// once all systems at the speed being updated have run, so every system sees the same entities.
+const (
+	FlushAfterSystem FlushMode = iota
+	FlushAfterUpdate
 )
 
 // Manager tracks all active systems and their update rates.
 type Manager struct {
+	Flush        FlushMode
 	systems      []System
 	systemSpeeds [NumSpeeds][]System
 }
@@ -28,16 +42,34 @@ func (m *Manager) AddSystem(speed Speed, system System) {
 	m.systemSpeeds[speed] = append(m.systemSpeeds[speed], system)
 }
 
-// Update calls the Update method on all of the active systems at the specified speed.
+// Update calls the Update method on all of the active systems at the specified speed, applying
+// deferred entity changes according to the manager's flush mode. The entity manager may be nil
+// for systems that do not use entities.
 func (m *Manager) Update(speed Speed, dt time.Duration, entities *entity.Manager) {
 	for _, s := range m.systemSpeeds[speed] {
 		s.Update(dt, entities)
+		if m.Flush == FlushAfterSystem && entities != nil {
+			entities.Flush()
+		}
+	}
+	if entities != nil {
+		entities.Flush()
 	}
 }
 
 // Draw calls the Draw method on all of the active systems.
-func (m *Manager) Draw(c *camera.Camera, entities *entity.Manager) {
+func (m *Manager) Draw(r render.Renderer, c *camera.Camera, entities *entity.Manager) {
+	for _, s := range m.systems {
+		s.Draw(r, c, entities)
+	}
+}
+
+// Close calls the Close method on all of the active systems that are Closers, so that they
+// release the resources they hold for the given entities.
+func (m *Manager) Close(entities *entity.Manager) {
 	for _, s := range m.systems {
-		s.Draw(c, entities)
+		if c, ok := s.(Closer); ok {
+			c.Close(entities)
+		}
 	}
 }
@@ -0,0 +1,43 @@
+package system
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FinnStokes/huge/camera"
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/render"
+)
+
+// spawner is a system that defers the creation of one entity per update and records how many
+// entities existed when it was updated.
+type spawner struct {
+	seen []int
+}
+
+func (s *spawner) Update(dt time.Duration, entities *entity.Manager) {
+	s.seen = append(s.seen, len(entities.All()))
+	entities.DeferNew(nil)
+}
+
+func (s *spawner) Draw(r render.Renderer, c *camera.Camera, entities *entity.Manager) {}
+
+func TestFlush(t *testing.T) {
+	for _, mode := range []FlushMode{FlushAfterSystem, FlushAfterUpdate} {
+		m := NewManager()
+		m.Flush = mode
+		first, second := new(spawner), new(spawner)
+		m.AddSystem(Normal, first)
+		m.AddSystem(Normal, second)
+		entities := entity.NewManager()
+		m.Update(Normal, time.Second, entities)
+		m.Update(Normal, time.Second, entities)
+		want := []int{0, 2}
+		if mode == FlushAfterSystem {
+			want = []int{1, 3}
+		}
+		if len(entities.All()) != 4 || second.seen[0] != want[0] || second.seen[1] != want[1] {
+			t.Errorf("Mode %v: second system saw %v entities, expected %v", mode, second.seen, want)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package system
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Speed is a type that specifies one of the defined system update rates.
 type Speed int
 
@@ -11,3 +16,24 @@ const (
 	Fast
 	NumSpeeds int = iota
 )
+
+var speedNames = [NumSpeeds]string{"slow", "normal", "fast"}
+
+// String returns the lower case name of the speed.
+func (s Speed) String() string {
+	if s < 0 || int(s) >= NumSpeeds {
+		return fmt.Sprintf("Speed(%d)", int(s))
+	}
+	return speedNames[s]
+}
+
+// UnmarshalText allows speeds to be given by name, such as "normal", in JSON files.
+func (s *Speed) UnmarshalText(text []byte) error {
+	for i, name := range speedNames {
+		if strings.EqualFold(string(text), name) {
+			*s = Speed(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("system: unknown speed %q", text)
+}
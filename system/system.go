@@ -6,10 +6,17 @@ import (
 
 	"github.com/FinnStokes/huge/camera"
 	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/render"
 )
 
 // System is an interface that must be satisfied by any system that is added to the system manager.
 type System interface {
 	Update(dt time.Duration, entities *entity.Manager)
-	Draw(c *camera.Camera, entities *entity.Manager)
+	Draw(r render.Renderer, c *camera.Camera, entities *entity.Manager)
+}
+
+// Closer is an interface satisfied by systems that hold on to resources for the entities they
+// work on, such as textures, which they release when Close is called with those entities.
+type Closer interface {
+	Close(entities *entity.Manager)
 }
@@ -0,0 +1,187 @@
+package tilemap
+
+import (
+	"image"
+	"math"
+	"time"
+
+	"github.com/FinnStokes/huge/camera"
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/render"
+	"github.com/FinnStokes/huge/sprite"
+)
+
+// Manager is a system that draws the tile layers of every entity with a tilemap component. Maps
+// are placed with their top left corner at the entity's position, or at the origin if it has no
+// pos component, and only the tiles within the camera's view, which may be rotated, are drawn.
+// Tileset textures are uploaded when first drawn and released once every entity manager drawn
+// with them has been closed.
+type Manager struct {
+	textures map[image.Image]render.Texture
+	users    map[image.Image]map[*entity.Manager]bool
+	batch    *render.Batch
+}
+
+// NewManager returns an initialised tile map manager.
+func NewManager() *Manager {
+	m := new(Manager)
+	m.textures = make(map[image.Image]render.Texture)
+	m.users = make(map[image.Image]map[*entity.Manager]bool)
+	m.batch = render.NewBatch()
+	return m
+}
+
+// Update does nothing, as tiles are not animated.
+func (m *Manager) Update(dt time.Duration, entities *entity.Manager) {}
+
+// Close releases the tileset textures that were only drawn for maps in entities, such as when
+// their scene is removed from the scene stack.
+func (m *Manager) Close(entities *entity.Manager) {
+	for img, users := range m.users {
+		if !users[entities] {
+			continue
+		}
+		delete(users, entities)
+		if len(users) > 0 {
+			continue
+		}
+		delete(m.users, img)
+		if tex, ok := m.textures[img]; ok {
+			tex.Release()
+			m.batch.Forget(tex)
+			delete(m.textures, img)
+		}
+	}
+}
+
+// Draw draws the visible tile layers of each map in order, with one draw call for each tileset
+// used by a layer. Maps are in sprite layer 0 for the purposes of the camera's layer mask, unless
+// their entity has a depth component.
+func (m *Manager) Draw(r render.Renderer, c *camera.Camera, entities *entity.Manager) {
+	maps := Maps(entities)
+	positions := entity.Positions(entities)
+	depths := sprite.Depths(entities)
+	for i, tm := range maps.Components() {
+		e := maps.Entities()[i]
+		layer := 0
+		if depth, ok := depths.Get(e); ok {
+			layer = depth.Layer
+		}
+		if !c.Shows(layer) {
+			continue
+		}
+		var x, y float32
+		if pos, ok := positions.Get(e); ok {
+			x, y = pos.X, pos.Y
+		}
+		for _, l := range tm.Layers {
+			if l.Type == TileLayer && l.Visible && l.Opacity > 0 {
+				m.drawLayer(r, c, entities, tm, l, x, y)
+				m.batch.Draw(r)
+			}
+		}
+	}
+}
+
+// drawLayer adds the layer's tiles that lie within the camera's view to the batch.
+func (m *Manager) drawLayer(r render.Renderer, c *camera.Camera, entities *entity.Manager, tm *Map, l *Layer, x, y float32) {
+	view := *c
+	px, py := l.parallax()
+	view.World.X *= px
+	view.World.Y *= py
+	world := view.Bounds()
+	x += l.OffsetX
+	y += l.OffsetY
+	// Tiles larger than the map's grid overhang the cells above and to the right of them.
+	overhangX, overhangY := 0, 0
+	for _, ts := range tm.Tilesets {
+		if d := ts.TileWidth - tm.TileWidth; d > overhangX {
+			overhangX = d
+		}
+		if d := ts.TileHeight - tm.TileHeight; d > overhangY {
+			overhangY = d
+		}
+	}
+	minCol, minRow := tm.Cell(world.X-x-float32(overhangX), world.Y-y)
+	maxCol := int(math.Ceil(float64((world.X+world.Width-x)/float32(tm.TileWidth)))) - 1
+	maxRow := int(math.Ceil(float64((world.Y+world.Height-y+float32(overhangY))/float32(tm.TileHeight)))) - 1
+	if minCol < 0 {
+		minCol = 0
+	}
+	if minRow < 0 {
+		minRow = 0
+	}
+	if maxCol >= tm.Width {
+		maxCol = tm.Width - 1
+	}
+	if maxRow >= tm.Height {
+		maxRow = tm.Height - 1
+	}
+
+	// Corners are snapped to whole pixels, so neighbouring tiles meet without gaps.
+	matrix := view.ViewProjection()
+	screen := func(wx, wy float32) (float32, float32) {
+		sx, sy := matrix.Apply(wx, wy)
+		return float32(math.Floor(float64(sx) + 0.5)), float32(math.Floor(float64(sy) + 0.5))
+	}
+	for row := minRow; row <= maxRow; row++ {
+		for col := minCol; col <= maxCol; col++ {
+			t := tm.TileAt(l, col, row)
+			ts := tm.Tileset(t)
+			if ts == nil || ts.Image == nil {
+				continue
+			}
+			x0 := x + float32(col*tm.TileWidth)
+			y1 := y + float32((row+1)*tm.TileHeight)
+			x1, y0 := x0+float32(ts.TileWidth), y1-float32(ts.TileHeight)
+			if !view.Visible(&camera.Rectangle{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}) {
+				continue
+			}
+			tex := m.texture(r, entities, ts.Image)
+			b := ts.Image.Bounds()
+			region := ts.Region(t.Gid() - ts.FirstGid).Sub(b.Min)
+			u0 := float32(region.Min.X) / float32(b.Dx())
+			v0 := float32(region.Min.Y) / float32(b.Dy())
+			u1 := float32(region.Max.X) / float32(b.Dx())
+			v1 := float32(region.Max.Y) / float32(b.Dy())
+			uv := [4][2]float32{{u0, v0}, {u1, v0}, {u1, v1}, {u0, v1}}
+			if t.FlipDiagonal() {
+				uv[1], uv[3] = uv[3], uv[1]
+			}
+			if t.FlipX() {
+				uv[0], uv[1], uv[2], uv[3] = uv[1], uv[0], uv[3], uv[2]
+			}
+			if t.FlipY() {
+				uv[0], uv[1], uv[2], uv[3] = uv[3], uv[2], uv[1], uv[0]
+			}
+
+			var quad [4]render.Vertex
+			for i, corner := range [4][2]float32{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}} {
+				sx, sy := screen(corner[0], corner[1])
+				quad[i] = render.Vertex{
+					X: sx, Y: sy,
+					U: uv[i][0], V: uv[i][1],
+					R: 1, G: 1, B: 1, A: l.Opacity,
+				}
+			}
+			m.batch.Add(tex, quad)
+		}
+	}
+}
+
+// texture returns the texture for img, uploading it if it has not been drawn before, and records
+// that it is used by entities.
+func (m *Manager) texture(r render.Renderer, entities *entity.Manager, img image.Image) render.Texture {
+	users, ok := m.users[img]
+	if !ok {
+		users = make(map[*entity.Manager]bool)
+		m.users[img] = users
+	}
+	users[entities] = true
+	tex, ok := m.textures[img]
+	if !ok {
+		tex = r.NewTexture(img)
+		m.textures[img] = tex
+	}
+	return tex
+}
@@ -0,0 +1,129 @@
+package tilemap
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/FinnStokes/huge/camera"
+	"github.com/FinnStokes/huge/entity"
+	"github.com/FinnStokes/huge/render"
+)
+
+// counter is a headless renderer that counts the quads drawn.
+type counter struct {
+	*render.Headless
+	quads int
+}
+
+func (c *counter) DrawQuads(tex render.Texture, quads []render.Vertex) {
+	c.quads += len(quads) / 4
+	c.Headless.DrawQuads(tex, quads)
+}
+
+var tileColors = []color.RGBA{{255, 0, 0, 255}, {0, 255, 0, 255}, {0, 0, 255, 255}, {255, 255, 0, 255}}
+
+func TestDraw(t *testing.T) {
+	// The tileset has four 8x8 tiles of solid colour, except that the right half of the third
+	// tile is white, so that it shows up white on the left when flipped.
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			img.SetRGBA(x, y, tileColors[y/8*2+x/8])
+			if y >= 8 && x >= 4 && x < 8 {
+				img.SetRGBA(x, y, color.RGBA{255, 255, 255, 255})
+			}
+		}
+	}
+	tm := &Map{
+		Width: 100, Height: 100, TileWidth: 8, TileHeight: 8,
+		Tilesets: []*Tileset{{FirstGid: 1, Image: img, TileWidth: 8, TileHeight: 8, Columns: 2, TileCount: 4}},
+	}
+	// Without parallax factors, the layer moves with the world.
+	ground := &Layer{Visible: true, Opacity: 1, Tiles: make([]Tile, 100*100)}
+	for i := range ground.Tiles {
+		ground.Tiles[i] = Tile(i%4 + 1)
+	}
+	ground.Tiles[1] = 3 | flipX
+	tm.Layers = []*Layer{ground, {Visible: false, Opacity: 1, Tiles: make([]Tile, 100*100)}}
+
+	h := render.NewHeadless(40, 40)
+	h.Open(40, 40, "")
+	r := &counter{Headless: h}
+	c := &camera.Camera{World: camera.Rectangle{X: 4, Y: 0, Width: 40, Height: 40}, Screen: camera.Screen{Width: 40, Height: 40}}
+	entities := entity.NewManager()
+	e := entities.New()
+	Maps(entities).Set(e, tm)
+	entity.Positions(entities).Set(e, &entity.Position{X: 4, Y: 0})
+	NewManager().Draw(r, c, entities)
+	h.SwapBuffers()
+
+	if r.quads != 25 {
+		t.Errorf("Drew %v tiles, expected the 25 in view", r.quads)
+	}
+	for _, p := range []struct {
+		x, y int
+		c    color.RGBA
+	}{
+		{1, 1, tileColors[0]},
+		{9, 1, color.RGBA{255, 255, 255, 255}},
+		{13, 1, tileColors[2]},
+		{17, 1, tileColors[2]},
+		{25, 1, tileColors[3]},
+		{1, 9, tileColors[0]},
+	} {
+		if got := h.Image().RGBAAt(p.x, p.y); got != p.c {
+			t.Errorf("Pixel at %v,%v is %v, expected %v", p.x, p.y, got, p.c)
+		}
+	}
+}
+
+// releaser is a headless renderer whose textures count how many times they are released.
+type releaser struct {
+	*render.Headless
+	released int
+}
+
+type releasedTexture struct {
+	render.Texture
+	r *releaser
+}
+
+func (t *releasedTexture) Release() {
+	t.r.released++
+}
+
+func (r *releaser) NewTexture(img image.Image) render.Texture {
+	return &releasedTexture{r.Headless.NewTexture(img), r}
+}
+
+func TestClose(t *testing.T) {
+	h := render.NewHeadless(40, 40)
+	h.Open(40, 40, "")
+	r := &releaser{Headless: h}
+	c := &camera.Camera{World: camera.Rectangle{Width: 40, Height: 40}, Screen: camera.Screen{Width: 40, Height: 40}}
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	newMap := func() *entity.Manager {
+		tm := &Map{
+			Width: 1, Height: 1, TileWidth: 8, TileHeight: 8,
+			Tilesets: []*Tileset{{FirstGid: 1, Image: img, TileWidth: 8, TileHeight: 8, Columns: 1, TileCount: 1}},
+			Layers:   []*Layer{{Visible: true, Opacity: 1, Tiles: []Tile{1}}},
+		}
+		entities := entity.NewManager()
+		Maps(entities).Set(entities.New(), tm)
+		return entities
+	}
+	a, b := newMap(), newMap()
+	m := NewManager()
+	m.Draw(r, c, a)
+	m.Draw(r, c, b)
+	m.Close(a)
+	if r.released != 0 {
+		t.Errorf("Released a tileset still drawn for other maps")
+	}
+	m.Close(b)
+	m.Close(b)
+	if r.released != 1 || len(m.textures) != 0 {
+		t.Errorf("Released %v textures and kept %v, expected 1 and 0", r.released, len(m.textures))
+	}
+}
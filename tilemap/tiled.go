@@ -0,0 +1,546 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// An Opener opens a file referred to by a map, such as an external tileset, given its path
+// relative to the map.
+type Opener func(path string) (io.ReadCloser, error)
+
+// mapSpec is a map in the form saved by Tiled, shared by the JSON and TMX formats.
+type mapSpec struct {
+	Orientation           string
+	Infinite              bool
+	Width, Height         int
+	TileWidth, TileHeight int
+	Layers                []layerSpec
+	Tilesets              []tilesetSpec
+	Properties            []propertySpec
+}
+
+type layerSpec struct {
+	Type                  string
+	Name                  string
+	Data                  json.RawMessage
+	Encoding, Compression string
+	Visible               *bool
+	Opacity               *float32
+	OffsetX, OffsetY      float32
+	ParallaxX, ParallaxY  *float32
+	Objects               []objectSpec
+	Layers                []layerSpec
+	Properties            []propertySpec
+	tiles                 []Tile
+}
+
+type objectSpec struct {
+	Id                  int
+	Name, Type, Class   string
+	X, Y, Width, Height float32
+	Rotation            float32
+	Gid                 uint32
+	Point, Ellipse      bool
+	Polygon             []Point
+	Properties          []propertySpec
+}
+
+type tilesetSpec struct {
+	FirstGid              int
+	Source                string
+	Name                  string
+	Image                 string
+	TileWidth, TileHeight int
+	Columns, TileCount    int
+	Spacing, Margin       int
+	Tiles                 []struct {
+		Id         int
+		Properties []propertySpec
+	}
+}
+
+type propertySpec struct {
+	Name, Type string
+	Value      interface{}
+}
+
+// ReadJSON reads a map saved by Tiled in its JSON format. External tilesets are read through
+// open, but tileset images are not loaded.
+func ReadJSON(r io.Reader, open Opener) (*Map, error) {
+	var spec mapSpec
+	if err := json.NewDecoder(r).Decode(&spec); err != nil {
+		return nil, err
+	}
+	if err := decodeLayers(spec.Layers, spec.Width*spec.Height); err != nil {
+		return nil, err
+	}
+	return spec.build(open)
+}
+
+// decodeLayers decodes the tile data of each layer, which is either an array of tiles or a
+// string of base64 encoded, optionally compressed, little endian tiles.
+func decodeLayers(layers []layerSpec, n int) error {
+	for i := range layers {
+		l := &layers[i]
+		if err := decodeLayers(l.Layers, n); err != nil {
+			return err
+		}
+		if l.Type != "tilelayer" {
+			continue
+		}
+		if l.Encoding == "base64" {
+			var s string
+			if err := json.Unmarshal(l.Data, &s); err != nil {
+				return err
+			}
+			tiles, err := decodeTiles(s, l.Encoding, l.Compression, n)
+			if err != nil {
+				return err
+			}
+			l.tiles = tiles
+		} else if err := json.Unmarshal(l.Data, &l.tiles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeTiles decodes n tiles given as text, either as comma separated values or base64.
+func decodeTiles(s, encoding, compression string, n int) ([]Tile, error) {
+	switch encoding {
+	case "csv":
+		var tiles []Tile
+		for _, field := range strings.Split(s, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			t, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			tiles = append(tiles, Tile(t))
+		}
+		return tiles, nil
+	case "base64":
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
+		}
+		var r io.Reader = bytes.NewReader(data)
+		switch compression {
+		case "":
+		case "zlib":
+			if r, err = zlib.NewReader(r); err != nil {
+				return nil, err
+			}
+		case "gzip":
+			if r, err = gzip.NewReader(r); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("tilemap: unsupported compression %q", compression)
+		}
+		tiles := make([]Tile, n)
+		if err := binary.Read(r, binary.LittleEndian, tiles); err != nil {
+			return nil, err
+		}
+		return tiles, nil
+	}
+	return nil, fmt.Errorf("tilemap: unsupported encoding %q", encoding)
+}
+
+// build converts the specification into a map, loading any external tilesets.
+func (spec *mapSpec) build(open Opener) (*Map, error) {
+	if spec.Orientation != "" && spec.Orientation != "orthogonal" {
+		return nil, fmt.Errorf("tilemap: unsupported orientation %q", spec.Orientation)
+	}
+	if spec.Infinite {
+		return nil, errors.New("tilemap: infinite maps are not supported")
+	}
+	m := &Map{
+		Width:      spec.Width,
+		Height:     spec.Height,
+		TileWidth:  spec.TileWidth,
+		TileHeight: spec.TileHeight,
+		Properties: properties(spec.Properties),
+	}
+	for _, ts := range spec.Tilesets {
+		dir := ""
+		if ts.Source != "" {
+			external, err := readTileset(ts.Source, open)
+			if err != nil {
+				return nil, err
+			}
+			external.FirstGid = ts.FirstGid
+			ts = *external
+			dir = path.Dir(ts.Source)
+		}
+		tileset := &Tileset{
+			Name:       ts.Name,
+			FirstGid:   ts.FirstGid,
+			ImagePath:  path.Join(dir, ts.Image),
+			TileWidth:  ts.TileWidth,
+			TileHeight: ts.TileHeight,
+			Columns:    ts.Columns,
+			TileCount:  ts.TileCount,
+			Spacing:    ts.Spacing,
+			Margin:     ts.Margin,
+			Tiles:      make(map[int]Properties),
+		}
+		for _, t := range ts.Tiles {
+			if len(t.Properties) > 0 {
+				tileset.Tiles[t.Id] = properties(t.Properties)
+			}
+		}
+		m.Tilesets = append(m.Tilesets, tileset)
+	}
+	base := Layer{Visible: true, Opacity: 1, ParallaxX: 1, ParallaxY: 1}
+	if err := m.addLayers(spec.Layers, base); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// readTileset reads an external tileset in either the TSX or JSON format.
+func readTileset(source string, open Opener) (*tilesetSpec, error) {
+	if open == nil {
+		return nil, fmt.Errorf("tilemap: cannot open external tileset %q", source)
+	}
+	f, err := open(source)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var ts tilesetSpec
+	if path.Ext(source) == ".tsx" {
+		var x tmxTileset
+		if err := xml.NewDecoder(f).Decode(&x); err != nil {
+			return nil, err
+		}
+		ts = x.spec()
+	} else if err := json.NewDecoder(f).Decode(&ts); err != nil {
+		return nil, err
+	}
+	ts.Source = source
+	return &ts, nil
+}
+
+// addLayers adds the layers to the map, flattening groups by combining their settings with those
+// of the layers within them.
+func (m *Map) addLayers(layers []layerSpec, parent Layer) error {
+	for _, spec := range layers {
+		l := &Layer{
+			Name:       spec.Name,
+			Visible:    parent.Visible && (spec.Visible == nil || *spec.Visible),
+			Opacity:    parent.Opacity,
+			OffsetX:    parent.OffsetX + spec.OffsetX,
+			OffsetY:    parent.OffsetY + spec.OffsetY,
+			ParallaxX:  parent.ParallaxX,
+			ParallaxY:  parent.ParallaxY,
+			Properties: properties(spec.Properties),
+		}
+		if spec.Opacity != nil {
+			l.Opacity *= *spec.Opacity
+		}
+		if spec.ParallaxX != nil {
+			l.ParallaxX *= *spec.ParallaxX
+		}
+		if spec.ParallaxY != nil {
+			l.ParallaxY *= *spec.ParallaxY
+		}
+		switch spec.Type {
+		case "group":
+			if err := m.addLayers(spec.Layers, *l); err != nil {
+				return err
+			}
+			continue
+		case "tilelayer":
+			if len(spec.tiles) != m.Width*m.Height {
+				return fmt.Errorf("tilemap: layer %q has %v tiles, expected %v", spec.Name, len(spec.tiles), m.Width*m.Height)
+			}
+			l.Type = TileLayer
+			l.Tiles = spec.tiles
+		case "objectgroup":
+			l.Type = ObjectLayer
+			for _, o := range spec.Objects {
+				object := &Object{
+					Id:         o.Id,
+					Name:       o.Name,
+					Type:       o.Type,
+					X:          o.X,
+					Y:          o.Y,
+					Width:      o.Width,
+					Height:     o.Height,
+					Rotation:   o.Rotation,
+					Tile:       Tile(o.Gid),
+					Point:      o.Point,
+					Ellipse:    o.Ellipse,
+					Polygon:    o.Polygon,
+					Properties: properties(o.Properties),
+				}
+				if object.Type == "" {
+					object.Type = o.Class
+				}
+				l.Objects = append(l.Objects, object)
+			}
+		default:
+			continue
+		}
+		m.Layers = append(m.Layers, l)
+	}
+	return nil
+}
+
+// properties converts a list of properties to a map, parsing values given as text.
+func properties(specs []propertySpec) Properties {
+	if len(specs) == 0 {
+		return nil
+	}
+	p := make(Properties, len(specs))
+	for _, s := range specs {
+		v := s.Value
+		if text, ok := v.(string); ok {
+			switch s.Type {
+			case "int", "float", "object":
+				if f, err := strconv.ParseFloat(text, 64); err == nil {
+					v = f
+				}
+			case "bool":
+				v = text == "true"
+			}
+		}
+		p[s.Name] = v
+	}
+	return p
+}
+
+// ReadTMX reads a map saved by Tiled in its XML based TMX format. External tilesets are read
+// through open, but tileset images are not loaded.
+func ReadTMX(r io.Reader, open Opener) (*Map, error) {
+	var x tmxMap
+	if err := xml.NewDecoder(r).Decode(&x); err != nil {
+		return nil, err
+	}
+	spec := mapSpec{
+		Orientation: x.Orientation,
+		Infinite:    x.Infinite == 1,
+		Width:       x.Width,
+		Height:      x.Height,
+		TileWidth:   x.TileWidth,
+		TileHeight:  x.TileHeight,
+		Properties:  tmxProperties(x.Properties),
+	}
+	for _, ts := range x.Tilesets {
+		spec.Tilesets = append(spec.Tilesets, ts.spec())
+	}
+	layers, err := tmxLayers(x.Layers, x.Width*x.Height)
+	if err != nil {
+		return nil, err
+	}
+	spec.Layers = layers
+	return spec.build(open)
+}
+
+type tmxMap struct {
+	Orientation string        `xml:"orientation,attr"`
+	Infinite    int           `xml:"infinite,attr"`
+	Width       int           `xml:"width,attr"`
+	Height      int           `xml:"height,attr"`
+	TileWidth   int           `xml:"tilewidth,attr"`
+	TileHeight  int           `xml:"tileheight,attr"`
+	Tilesets    []tmxTileset  `xml:"tileset"`
+	Properties  []tmxProperty `xml:"properties>property"`
+	Layers      []tmxLayer    `xml:",any"`
+}
+
+type tmxTileset struct {
+	FirstGid   int    `xml:"firstgid,attr"`
+	Source     string `xml:"source,attr"`
+	Name       string `xml:"name,attr"`
+	TileWidth  int    `xml:"tilewidth,attr"`
+	TileHeight int    `xml:"tileheight,attr"`
+	TileCount  int    `xml:"tilecount,attr"`
+	Columns    int    `xml:"columns,attr"`
+	Spacing    int    `xml:"spacing,attr"`
+	Margin     int    `xml:"margin,attr"`
+	Image      struct {
+		Source string `xml:"source,attr"`
+	} `xml:"image"`
+	Tiles []struct {
+		Id         int           `xml:"id,attr"`
+		Properties []tmxProperty `xml:"properties>property"`
+	} `xml:"tile"`
+}
+
+func (x *tmxTileset) spec() tilesetSpec {
+	ts := tilesetSpec{
+		FirstGid:   x.FirstGid,
+		Source:     x.Source,
+		Name:       x.Name,
+		Image:      x.Image.Source,
+		TileWidth:  x.TileWidth,
+		TileHeight: x.TileHeight,
+		Columns:    x.Columns,
+		TileCount:  x.TileCount,
+		Spacing:    x.Spacing,
+		Margin:     x.Margin,
+	}
+	for _, t := range x.Tiles {
+		ts.Tiles = append(ts.Tiles, struct {
+			Id         int
+			Properties []propertySpec
+		}{t.Id, tmxProperties(t.Properties)})
+	}
+	return ts
+}
+
+// tmxLayer holds any kind of layer, which are told apart by their element name.
+type tmxLayer struct {
+	XMLName    xml.Name
+	Name       string        `xml:"name,attr"`
+	Visible    *int          `xml:"visible,attr"`
+	Opacity    *float32      `xml:"opacity,attr"`
+	OffsetX    float32       `xml:"offsetx,attr"`
+	OffsetY    float32       `xml:"offsety,attr"`
+	ParallaxX  *float32      `xml:"parallaxx,attr"`
+	ParallaxY  *float32      `xml:"parallaxy,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Data       struct {
+		Encoding    string `xml:"encoding,attr"`
+		Compression string `xml:"compression,attr"`
+		Text        string `xml:",chardata"`
+		Tiles       []struct {
+			Gid uint32 `xml:"gid,attr"`
+		} `xml:"tile"`
+	} `xml:"data"`
+	Objects []tmxObject `xml:"object"`
+	Layers  []tmxLayer  `xml:",any"`
+}
+
+type tmxObject struct {
+	Id         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float32       `xml:"x,attr"`
+	Y          float32       `xml:"y,attr"`
+	Width      float32       `xml:"width,attr"`
+	Height     float32       `xml:"height,attr"`
+	Rotation   float32       `xml:"rotation,attr"`
+	Gid        uint32        `xml:"gid,attr"`
+	Point      *struct{}     `xml:"point"`
+	Ellipse    *struct{}     `xml:"ellipse"`
+	Polygon    *tmxPolygon   `xml:"polygon"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxPolygon struct {
+	Points string `xml:"points,attr"`
+}
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Type  string `xml:"type,attr"`
+	Value string `xml:"value,attr"`
+	Text  string `xml:",chardata"`
+}
+
+func tmxProperties(x []tmxProperty) []propertySpec {
+	specs := make([]propertySpec, len(x))
+	for i, p := range x {
+		value := p.Value
+		if value == "" {
+			value = p.Text
+		}
+		specs[i] = propertySpec{p.Name, p.Type, value}
+	}
+	return specs
+}
+
+// tmxLayers converts the layers to their common form, decoding the tile data.
+func tmxLayers(layers []tmxLayer, n int) ([]layerSpec, error) {
+	var specs []layerSpec
+	for _, x := range layers {
+		spec := layerSpec{
+			Name:       x.Name,
+			Opacity:    x.Opacity,
+			OffsetX:    x.OffsetX,
+			OffsetY:    x.OffsetY,
+			ParallaxX:  x.ParallaxX,
+			ParallaxY:  x.ParallaxY,
+			Properties: tmxProperties(x.Properties),
+		}
+		if x.Visible != nil {
+			visible := *x.Visible != 0
+			spec.Visible = &visible
+		}
+		switch x.XMLName.Local {
+		case "layer":
+			spec.Type = "tilelayer"
+			if x.Data.Encoding == "" {
+				for _, t := range x.Data.Tiles {
+					spec.tiles = append(spec.tiles, Tile(t.Gid))
+				}
+			} else {
+				tiles, err := decodeTiles(x.Data.Text, x.Data.Encoding, x.Data.Compression, n)
+				if err != nil {
+					return nil, err
+				}
+				spec.tiles = tiles
+			}
+		case "objectgroup":
+			spec.Type = "objectgroup"
+			for _, o := range x.Objects {
+				object := objectSpec{
+					Id:         o.Id,
+					Name:       o.Name,
+					Type:       o.Type,
+					Class:      o.Class,
+					X:          o.X,
+					Y:          o.Y,
+					Width:      o.Width,
+					Height:     o.Height,
+					Rotation:   o.Rotation,
+					Gid:        o.Gid,
+					Point:      o.Point != nil,
+					Ellipse:    o.Ellipse != nil,
+					Properties: tmxProperties(o.Properties),
+				}
+				if o.Polygon != nil {
+					for _, pair := range strings.Fields(o.Polygon.Points) {
+						var p Point
+						if _, err := fmt.Sscanf(pair, "%g,%g", &p.X, &p.Y); err != nil {
+							return nil, fmt.Errorf("tilemap: invalid polygon point %q", pair)
+						}
+						object.Polygon = append(object.Polygon, p)
+					}
+				}
+				spec.Objects = append(spec.Objects, object)
+			}
+		case "group":
+			spec.Type = "group"
+			children, err := tmxLayers(x.Layers, n)
+			if err != nil {
+				return nil, err
+			}
+			spec.Layers = children
+		default:
+			continue
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
@@ -0,0 +1,132 @@
+package tilemap
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The tiles of the test maps, with the fourth tile flipped horizontally.
+var testTiles = []Tile{1, 2, 0, 3 | flipX, 4, 1, 2, 3}
+
+const testJSON = `{
+	"orientation": "orthogonal", "width": 4, "height": 2, "tilewidth": 8, "tileheight": 8,
+	"properties": [{"name": "music", "type": "string", "value": "theme"}],
+	"tilesets": [
+		{"firstgid": 1, "name": "ground", "image": "ground.png", "tilewidth": 8, "tileheight": 8,
+			"columns": 2, "tilecount": 4, "spacing": 0, "margin": 0,
+			"tiles": [{"id": 2, "properties": [{"name": "solid", "type": "bool", "value": true}]}]},
+		{"firstgid": 5, "source": "props.tsj"}
+	],
+	"layers": [
+		{"type": "tilelayer", "name": "ground", "width": 4, "height": 2, "visible": true, "opacity": 1,
+			"data": [1, 2, 0, 2147483651, 4, 1, 2, 3]},
+		{"type": "group", "name": "front", "offsetx": 4, "opacity": 0.5, "layers": [
+			{"type": "tilelayer", "name": "detail", "width": 4, "height": 2, "opacity": 0.5, "offsety": 2,
+				"encoding": "base64", "compression": "zlib", "data": "eJxjZGBgYGKAAGYGhgYWIM0IFQPyGQAJnACR"},
+			{"type": "objectgroup", "name": "spawns", "objects": [
+				{"id": 1, "name": "start", "type": "player", "x": 16, "y": 8, "width": 8, "height": 8, "point": false,
+					"properties": [{"name": "lives", "type": "int", "value": 3}]},
+				{"id": 2, "class": "platform", "x": 0, "y": 0, "polygon": [{"x": 0, "y": 0}, {"x": 8, "y": 4}]}
+			]}
+		]},
+		{"type": "imagelayer", "name": "sky"}
+	]
+}`
+
+const testTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" width="4" height="2" tilewidth="8" tileheight="8" infinite="0">
+ <properties><property name="music" value="theme"/></properties>
+ <tileset firstgid="1" name="ground" tilewidth="8" tileheight="8" tilecount="4" columns="2">
+  <image source="ground.png" width="16" height="16"/>
+  <tile id="2"><properties><property name="solid" type="bool" value="true"/></properties></tile>
+ </tileset>
+ <tileset firstgid="5" source="props.tsx"/>
+ <layer id="1" name="ground" width="4" height="2">
+  <data encoding="csv">
+1,2,0,2147483651,
+4,1,2,3
+</data>
+ </layer>
+ <group name="front" offsetx="4" opacity="0.5">
+  <layer id="2" name="detail" width="4" height="2" opacity="0.5" offsety="2">
+   <data encoding="base64" compression="zlib">eJxjZGBgYGKAAGYGhgYWIM0IFQPyGQAJnACR</data>
+  </layer>
+  <objectgroup id="3" name="spawns">
+   <object id="1" name="start" type="player" x="16" y="8" width="8" height="8">
+    <properties><property name="lives" type="int" value="3"/></properties>
+   </object>
+   <object id="2" class="platform" x="0" y="0"><polygon points="0,0 8,4"/></object>
+  </objectgroup>
+ </group>
+ <imagelayer id="4" name="sky"/>
+</map>`
+
+// testOpener opens the external tilesets of the test maps.
+func testOpener(p string) (io.ReadCloser, error) {
+	files := map[string]string{
+		"props.tsj": `{"name": "props", "image": "art/props.png", "tilewidth": 16, "tileheight": 16, "columns": 1, "tilecount": 1}`,
+		"props.tsx": `<tileset name="props" tilewidth="16" tileheight="16" tilecount="1" columns="1"><image source="art/props.png"/></tileset>`,
+	}
+	return io.NopCloser(strings.NewReader(files[p])), nil
+}
+
+func checkMap(t *testing.T, m *Map) {
+	if m.Width != 4 || m.Height != 2 || m.TileWidth != 8 || m.TileHeight != 8 || m.Properties.String("music") != "theme" {
+		t.Errorf("Read %vx%v map of %vx%v tiles with properties %v", m.Width, m.Height, m.TileWidth, m.TileHeight, m.Properties)
+	}
+	if len(m.Layers) != 3 {
+		t.Fatalf("Read %v layers, expected 3", len(m.Layers))
+	}
+	ground, detail, spawns := m.Layers[0], m.Layers[1], m.Layers[2]
+	if ground.Name != "ground" || !reflect.DeepEqual(ground.Tiles, testTiles) {
+		t.Errorf("Read ground layer %q with tiles %v, expected %v", ground.Name, ground.Tiles, testTiles)
+	}
+	if !reflect.DeepEqual(detail.Tiles, testTiles) || detail.Opacity != 0.25 || detail.OffsetX != 4 || detail.OffsetY != 2 {
+		t.Errorf("Read detail layer with tiles %v, opacity %v and offset %v,%v", detail.Tiles, detail.Opacity, detail.OffsetX, detail.OffsetY)
+	}
+	if spawns.Type != ObjectLayer || len(spawns.Objects) != 2 {
+		t.Fatalf("Read %v objects, expected 2", len(spawns.Objects))
+	}
+	start, platform := spawns.Objects[0], spawns.Objects[1]
+	if start.Name != "start" || start.Type != "player" || start.X != 16 || start.Properties.Float("lives") != 3 {
+		t.Errorf("Read object %+v", start)
+	}
+	if platform.Type != "platform" || !reflect.DeepEqual(platform.Polygon, []Point{{0, 0}, {8, 4}}) {
+		t.Errorf("Read object %+v", platform)
+	}
+
+	if tile := m.TileAt(ground, 3, 0); tile.Gid() != 3 || !tile.FlipX() || tile.FlipY() {
+		t.Errorf("Tile at 3,0 is %v, expected 3 flipped horizontally", tile.Gid())
+	}
+	if tile := m.TileAt(ground, 4, 0); tile != 0 {
+		t.Errorf("Tile outside map is %v, expected empty", tile)
+	}
+	if !m.TileProperties(m.TileAt(ground, 3, 1)).Bool("solid") || m.TileProperties(m.TileAt(ground, 0, 0)).Bool("solid") {
+		t.Error("Tile 3 should be the only solid tile")
+	}
+	if len(m.Tilesets) != 2 {
+		t.Fatalf("Read %v tilesets, expected 2", len(m.Tilesets))
+	}
+	props := m.Tilesets[1]
+	if props.FirstGid != 5 || props.ImagePath != "art/props.png" || props.TileWidth != 16 || m.Tileset(Tile(5)) != props {
+		t.Errorf("Read external tileset %+v", props)
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	m, err := ReadJSON(strings.NewReader(testJSON), testOpener)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkMap(t, m)
+}
+
+func TestReadTMX(t *testing.T) {
+	m, err := ReadTMX(strings.NewReader(testTMX), testOpener)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkMap(t, m)
+}
@@ -0,0 +1,225 @@
+// Package tilemap implements grid based levels made of tiles, as created with the Tiled map
+// editor, along with a system to draw them.
+package tilemap
+
+import (
+	"image"
+	"math"
+
+	"github.com/FinnStokes/huge/entity"
+)
+
+// A Map is a grid of tiles in one or more layers, along with layers of objects placed in the
+// level. Positions within the map are in pixels from its top left corner, which is placed in the
+// world at the position of the entity holding the map.
+type Map struct {
+	// Name is the name of the resource the map was loaded from, if any.
+	Name                  string
+	Width, Height         int
+	TileWidth, TileHeight int
+	Layers                []*Layer
+	Tilesets              []*Tileset
+	Properties            Properties
+}
+
+// LayerType distinguishes layers of tiles from layers of objects.
+type LayerType int
+
+const (
+	TileLayer LayerType = iota
+	ObjectLayer
+)
+
+// A Layer is a grid of tiles or a set of objects. The tiles are stored row by row, and a zero
+// tile is empty.
+type Layer struct {
+	Name    string
+	Type    LayerType
+	Tiles   []Tile
+	Objects []*Object
+	Visible bool
+	Opacity float32
+	// OffsetX and OffsetY shift the whole layer, in pixels.
+	OffsetX, OffsetY float32
+	// ParallaxX and ParallaxY scale how far the layer moves as the camera moves, as for sprite
+	// layers. A factor of 0 is taken to be 1, so that layers built in code move with the world.
+	ParallaxX, ParallaxY float32
+	Properties           Properties
+}
+
+// parallax returns the layer's parallax factors, with zero factors taken to be 1.
+func (l *Layer) parallax() (x, y float32) {
+	x, y = l.ParallaxX, l.ParallaxY
+	if x == 0 {
+		x = 1
+	}
+	if y == 0 {
+		y = 1
+	}
+	return x, y
+}
+
+// A Tile is a global tile id, which identifies both a tileset and a tile within it, along with
+// flags for how the tile is flipped.
+type Tile uint32
+
+const (
+	flipX        Tile = 1 << 31
+	flipY        Tile = 1 << 30
+	flipDiagonal Tile = 1 << 29
+	flipMask          = flipX | flipY | flipDiagonal
+)
+
+// Gid returns the global tile id without the flip flags, which is zero for an empty tile.
+func (t Tile) Gid() int {
+	return int(t &^ flipMask)
+}
+
+// FlipX returns true if the tile is mirrored from left to right.
+func (t Tile) FlipX() bool {
+	return t&flipX != 0
+}
+
+// FlipY returns true if the tile is mirrored from top to bottom.
+func (t Tile) FlipY() bool {
+	return t&flipY != 0
+}
+
+// FlipDiagonal returns true if the tile is mirrored across the diagonal from its top left to its
+// bottom right corner, which is applied before the other flips.
+func (t Tile) FlipDiagonal() bool {
+	return t&flipDiagonal != 0
+}
+
+// A Tileset is an image divided into tiles, which are numbered from FirstGid onwards.
+type Tileset struct {
+	Name     string
+	FirstGid int
+	// ImagePath is the path to the tileset's image relative to the map, and Image is the image
+	// once it has been loaded.
+	ImagePath             string
+	Image                 image.Image
+	TileWidth, TileHeight int
+	Columns, TileCount    int
+	Spacing, Margin       int
+	// Tiles holds the properties of individual tiles, by their id within the tileset.
+	Tiles map[int]Properties
+}
+
+// Region returns the bounds within the tileset's image of the tile with the given id within the
+// tileset.
+func (ts *Tileset) Region(id int) image.Rectangle {
+	columns := ts.Columns
+	if columns <= 0 {
+		columns = 1
+	}
+	x := ts.Margin + id%columns*(ts.TileWidth+ts.Spacing)
+	y := ts.Margin + id/columns*(ts.TileHeight+ts.Spacing)
+	min := image.Pt(x, y)
+	if ts.Image != nil {
+		min = min.Add(ts.Image.Bounds().Min)
+	}
+	return image.Rectangle{min, min.Add(image.Pt(ts.TileWidth, ts.TileHeight))}
+}
+
+// An Object is a shape or point placed in an object layer. Polygon points are relative to the
+// object's position.
+type Object struct {
+	Id             int
+	Name, Type     string
+	X, Y           float32
+	Width, Height  float32
+	Rotation       float32
+	Tile           Tile
+	Point, Ellipse bool
+	Polygon        []Point
+	Properties     Properties
+}
+
+// A Point is a position in pixels.
+type Point struct {
+	X, Y float32
+}
+
+// Objects returns the entity manager's store of the objects that entities were created from,
+// registered as "object".
+func Objects(m *entity.Manager) *entity.ComponentStore[*Object] {
+	return entity.Register[*Object](m, "object")
+}
+
+// Maps returns the entity manager's store of tile maps, registered as "tilemap".
+func Maps(m *entity.Manager) *entity.ComponentStore[*Map] {
+	return entity.Register[*Map](m, "tilemap")
+}
+
+// Properties holds the custom properties set in the editor. Values are strings, float64s for
+// numbers or bools.
+type Properties map[string]interface{}
+
+// String returns the named property if it is a string, or the empty string.
+func (p Properties) String(name string) string {
+	s, _ := p[name].(string)
+	return s
+}
+
+// Float returns the named property if it is a number, or zero.
+func (p Properties) Float(name string) float64 {
+	f, _ := p[name].(float64)
+	return f
+}
+
+// Bool returns the named property if it is a bool, or false.
+func (p Properties) Bool(name string) bool {
+	b, _ := p[name].(bool)
+	return b
+}
+
+// Layer returns the first layer with the given name, or nil if there is none.
+func (m *Map) Layer(name string) *Layer {
+	for _, l := range m.Layers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// TileAt returns the tile at the given column and row of the layer, or an empty tile if it lies
+// outside the map.
+func (m *Map) TileAt(l *Layer, x, y int) Tile {
+	if l.Type != TileLayer || x < 0 || y < 0 || x >= m.Width || y >= m.Height || y*m.Width+x >= len(l.Tiles) {
+		return 0
+	}
+	return l.Tiles[y*m.Width+x]
+}
+
+// Tileset returns the tileset that the tile belongs to, or nil for an empty tile.
+func (m *Map) Tileset(t Tile) *Tileset {
+	var found *Tileset
+	gid := t.Gid()
+	if gid == 0 {
+		return nil
+	}
+	for _, ts := range m.Tilesets {
+		if ts.FirstGid <= gid && (found == nil || ts.FirstGid > found.FirstGid) {
+			found = ts
+		}
+	}
+	return found
+}
+
+// TileProperties returns the properties of the tile set in its tileset, such as whether it is
+// solid, or nil if it has none.
+func (m *Map) TileProperties(t Tile) Properties {
+	ts := m.Tileset(t)
+	if ts == nil {
+		return nil
+	}
+	return ts.Tiles[t.Gid()-ts.FirstGid]
+}
+
+// Cell returns the column and row of the tile containing the point, given in pixels from the top
+// left of the map.
+func (m *Map) Cell(x, y float32) (column, row int) {
+	return int(math.Floor(float64(x / float32(m.TileWidth)))), int(math.Floor(float64(y / float32(m.TileHeight))))
+}